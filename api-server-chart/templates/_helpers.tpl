@@ -38,3 +38,30 @@ Selector labels
 app.kubernetes.io/name: {{ include "chart.name" . }}
 app.kubernetes.io/instance: {{ .Release.Name }}
 {{- end }}
+
+{{/*
+Git credential helper reading the credentialsSecret, for the git-clone init container and the api-server
+*/}}
+{{- define "api-server.gitCredentials" -}}
+{{- if .Values.gitops.credentialsSecret }}
+- name: GIT_CONFIG_COUNT
+  value: "1"
+- name: GIT_CONFIG_KEY_0
+  value: credential.helper
+- name: GIT_CONFIG_VALUE_0
+  value: store --file=/etc/platform/git/.git-credentials
+{{- end }}
+{{- end }}
+
+{{/*
+GitOps working copy and credentials mounts
+*/}}
+{{- define "api-server.gitMounts" -}}
+- name: gitops
+  mountPath: /var/lib/gitops
+{{- if .Values.gitops.credentialsSecret }}
+- name: git-credentials
+  mountPath: /etc/platform/git
+  readOnly: true
+{{- end }}
+{{- end }}
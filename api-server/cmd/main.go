@@ -1,48 +1,288 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"api-server/internal/audit"
+	"api-server/internal/gitops"
 	h "api-server/internal/handler"
+	"api-server/internal/health"
 	m "api-server/internal/metrics"
+	"api-server/internal/notify"
+	"api-server/internal/openapi"
+	"api-server/internal/pricing"
+	"api-server/internal/tracing"
 )
 
+// version is overridden at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
 	// Chi allows you to route/handle any HTTP request method, such as all the usual suspects: GET, POST, HEAD, PUT, PATCH, DELETE, OPTIONS, TRACE, CONNECT
 	// Routing refers to how an application's endpoints (URIs) respond to client requests.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, version)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	defer func() { _ = shutdownTracing(context.Background()) }()
+
+	// namespaces outside METRICS_NAMESPACES are reported as "other" in claim metrics
+	var namespaces []string
+	if v := os.Getenv("METRICS_NAMESPACES"); v != "" {
+		namespaces = strings.Split(v, ",")
+	}
+	metrics := m.InitPrometheus(namespaces...)
+
+	// the user named by the authenticating proxy is only trusted on requests from TRUSTED_PROXIES, others are
+	// anonymous and cannot see connection details
+	proxies, err := audit.ParseProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("❌ Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Use(metrics.Middleware(r))
 	r.Use(middleware.RequestID)
+	r.Use(audit.TrustProxies(proxies)) // before RealIP rewrites RemoteAddr
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second)) // context deadline
+	r.Use(h.SelectCluster)                      // ?cluster= targets one of CLUSTERS
+
+	// CLUSTERS lists the clusters claims can target, the first one being the default whose health gates /readyz;
+	// without it the api-server manages the cluster it runs in
+	var clusters *h.Clusters
+	var client *h.KubeClient
+	if v := os.Getenv("CLUSTERS"); v != "" {
+		cfgs, err := h.ParseClusters(v)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		members := make([]*h.Cluster, 0, len(cfgs))
+		for _, cfg := range cfgs {
+			k, err := h.NewClusterClient(cfg)
+			if err != nil {
+				log.Fatalf("❌ %v", err)
+			}
+			discoverClaimKinds(ctx, k)
+			members = append(members, &h.Cluster{KubeClient: k, Name: cfg.Name, Environment: cfg.Environment, Checks: clusterChecks(k)})
+		}
+		clusters = h.NewClusters(members...)
+		client = clusters.Default().KubeClient
+	} else {
+		if client, err = h.NewKubernetesClient(); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		discoverClaimKinds(ctx, client)
+	}
+	if err := h.LoadTemplates(); err != nil {
+		log.Printf("❌ %v", err) // reported by /readyz
+	}
+
+	// webhooks are stored in a Secret next to the api-server, or in memory when running outside the cluster
+	var store notify.Store = notify.NewMemoryStore()
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		store = &notify.SecretStore{Clientset: client.Clientset, Namespace: ns, Name: "api-server-webhooks"}
+	}
+	notifier := notify.New(store)
+	expiringWithin := 5 * time.Minute
+	if v := os.Getenv("NOTIFY_EXPIRING_WITHIN"); v != "" {
+		if expiringWithin, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("❌ Invalid NOTIFY_EXPIRING_WITHIN: %v", err)
+		}
+	}
+
+	// with GITOPS_DIR, claims are committed to a working copy of the repository ArgoCD syncs instead of
+	// created in the cluster, and claims the claim-controller expires are removed from it
+	var claimer h.Claimer = client
+	if clusters != nil {
+		claimer = clusters
+	}
+	publish := notifier.Publish
+	if dir := os.Getenv("GITOPS_DIR"); dir != "" {
+		if clusters != nil {
+			log.Fatalf("❌ GITOPS_DIR and CLUSTERS are exclusive: the GitOps repository is synced into a single cluster")
+		}
+		repo, err := gitops.Open(ctx, dir, os.Getenv("GITOPS_REMOTE"), envOr("GITOPS_BRANCH", "main"))
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		git := &h.GitClaimer{
+			KubeClient:  client,
+			Repo:        repo,
+			Root:        envOr("GITOPS_PATH", "tenants"),
+			EmailDomain: envOr("GITOPS_AUTHOR_DOMAIN", "platform.example.org"),
+		}
+		claimer = git
+		publish = git.PruneExpired(ctx, publish)
+	}
+	watched := []*h.Cluster{{KubeClient: client}}
+	if clusters != nil {
+		watched = clusters.All()
+	}
+	for _, cl := range watched {
+		if err := cl.WatchClaimEvents(ctx, publish, expiringWithin); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		cl.StartInformers(ctx)
+	}
+	go notifier.Run(ctx)
+
+	// claim inventory is read from the informer caches on scrape, claims not Ready after notReadyAfter count as stalled
+	notReadyAfter := 15 * time.Minute
+	if v := os.Getenv("CLAIM_NOT_READY_THRESHOLD"); v != "" {
+		if notReadyAfter, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("❌ Invalid CLAIM_NOT_READY_THRESHOLD: %v", err)
+		}
+	}
+	var inventory m.Inventory = client
+	if clusters != nil {
+		inventory = clusters // every cluster's claims, unreachable clusters left out
+	}
+	metrics.RegisterInventory(inventory, notReadyAfter)
+
+	// without a pricing table every estimate is unavailable, submissions still work
+	prices := &pricing.Table{Currency: "USD"}
+	if path := os.Getenv("PRICING_FILE"); path != "" {
+		if prices, err = pricing.Load(path); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+	metrics.RegisterCosts(inventory, prices)
+
+	// /livez only proves the process serves HTTP so a kube-apiserver outage does not restart every replica,
+	// /readyz gates traffic (and the startup probe) on everything a request depends on
+	// other clusters only report their health at /api/v1/clusters, so one outage does not take the api-server down
+	claims := client.ClaimGVRs()
+	readyChecks := append([]health.Check{health.Ping}, clusterChecks(client)...)
+	readyChecks = append(readyChecks, health.Check{Name: "templates", Fn: h.TemplatesLoaded})
+	r.Get("/livez", health.Handler(5*time.Second, health.Ping))
+	r.Get("/readyz", health.Handler(5*time.Second, readyChecks...))
+	r.Get("/healthz", health.Handler(5*time.Second, readyChecks...))
 
-	client := h.NewKubernetesClient()
-	metrics := m.InitPrometheus()
 	r.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
 
+	var ttlBounds map[h.Resource]h.TTLBounds
+	if v := os.Getenv("TTL_BOUNDS"); v != "" {
+		if ttlBounds, err = h.ParseTTLBounds(v); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+
+	// cost-allocation tags are optional unless TAG_POLICY requires them
+	var tagPolicy h.TagPolicy
+	if v := os.Getenv("TAG_POLICY"); v != "" {
+		if tagPolicy, err = h.ParseTagPolicy(v); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+
 	handler := &h.Handler{
-		Claimer: client, // client is NewKubernetesClient()
-		Metrics: metrics,
+		Claimer:   claimer, // client is NewKubernetesClient(), or a GitClaimer wrapping it
+		Metrics:   metrics,
+		Pricing:   prices,
+		Notifier:  notifier,
+		TTLBounds: ttlBounds,
+		Audit:     audit.New(os.Stdout), // JSON lines next to the request log
+		TagPolicy: tagPolicy,
+		Clusters:  clusters,
 	}
 
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "/web/templates/index.html")
+		http.ServeFile(w, r, filepath.Join(h.TemplateDir, "index.html"))
 	})
 	// This tells Chi to match paths like /view/MyClaim, and now MakeHandler will receive the correct r.URL.Path value and extract MyClaim.
-	r.Get("/view/{name}", h.MakeHandler(h.ViewHandler))
+	r.Get("/view/{name}", h.MakeHandler(handler.ViewHandler))
 	r.Get("/edit/{name}", h.MakeHandler(h.EditHandler))
+	r.Post("/extend/{name}", h.MakeHandler(handler.ExtendHandler))
+	r.Post("/clone/{name}", h.MakeHandler(handler.CloneHandler))
 	r.Post("/submit", handler.SubmitHandler)
 	r.Post("/submit/{name}", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/", http.StatusFound) })
 	r.Get("/claims", handler.GetClaims)
+	r.Get("/webhooks", handler.WebhooksHandler)
+	r.Post("/webhooks", handler.SaveWebhookHandler)
+
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/claims", handler.ListClaimsAPI)
+		r.Post("/claims", handler.CreateClaimAPI)
+		r.Get("/claims/{type}/{namespace}/{name}", handler.GetClaimAPI)
+		r.Patch("/claims/{type}/{namespace}/{name}", handler.UpdateClaimAPI)
+		r.Delete("/claims/{type}/{namespace}/{name}", handler.DeleteClaimAPI)
+		r.Post("/claims/{type}/{namespace}/{name}/extend", handler.ExtendClaimAPI)
+		r.Get("/claims/{type}/{namespace}/{name}/connection", handler.ConnectionAPI)
+		r.Post("/claims/{type}/{namespace}/{name}/activity", handler.ActivityAPI)
+		r.Post("/claims/{type}/{namespace}/{name}/clone", handler.CloneClaimAPI)
+		r.Get("/clusters", handler.ClustersAPI)
+		r.Get("/estimate", handler.EstimateAPI)
+		r.Get("/costs", handler.CostsAPI)
+		r.Get("/webhooks/{namespace}", handler.GetWebhookAPI)
+		r.Put("/webhooks/{namespace}", handler.PutWebhookAPI)
+		r.Delete("/webhooks/{namespace}", handler.DeleteWebhookAPI)
+		r.Get("/webhooks/{namespace}/deadletters", handler.DeadLettersAPI)
+	})
+
+	kinds := make([]openapi.Kind, 0, len(claims))
+	for _, gvr := range claims {
+		kinds = append(kinds, openapi.Kind{Resource: gvr.Resource, Group: gvr.Group, Version: gvr.Version, Kind: client.KindOf(h.Resource(gvr.Resource))})
+	}
+	r.Get("/openapi.json", openapi.Handler(openapi.Build(version, kinds, h.Regions)))
+
+	srv := &http.Server{Addr: ":8080", Handler: r}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
 
 	fmt.Println("Starting server...")
-	log.Fatal(http.ListenAndServe(":8080", r))
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// discoverClaimKinds serves the claim kinds offered by the XRDs of a cluster, or only the default ones when they
+// cannot be listed so that a kube-apiserver outage does not keep the api-server from starting
+func discoverClaimKinds(ctx context.Context, k *h.KubeClient) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := k.DiscoverClaimKinds(ctx); err != nil {
+		log.Printf("❌ %v, serving %v", err, k.ClaimGVRs())
+	}
+}
+
+// clusterChecks probe what claims depend on in a cluster
+func clusterChecks(k *h.KubeClient) []health.Check {
+	claims := k.ClaimGVRs()
+	return []health.Check{
+		health.KubeAPIServer(k.Clientset),
+		health.XRDs(k.DynamicClient, claims),
+		health.CRDs(k.DynamicClient, claims),
+		{Name: "informer-sync", Fn: k.InformersSynced},
+	}
 }
@@ -0,0 +1,125 @@
+// Package audit records who changed which claim as JSON lines, one entry per line, so the
+// trail can be shipped with the container logs and queried apart from the request log
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ActorHeader is set by the authenticating proxy in front of the api-server
+const ActorHeader = "X-Forwarded-User"
+
+// Entry is one audited action on a claim
+type Entry struct {
+	Time      time.Time         `json:"time"`
+	Action    string            `json:"action"` // i.e. claim.extend
+	Actor     string            `json:"actor"`
+	Kind      string            `json:"kind"`
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Outcome   string            `json:"outcome"` // allowed or denied
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+const (
+	Allowed = "allowed"
+	Denied  = "denied"
+)
+
+// Logger writes entries to w; a nil Logger discards them
+type Logger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func New(w io.Writer) *Logger {
+	return &Logger{enc: json.NewEncoder(w)}
+}
+
+// Record writes e, setting its time when unset
+func (l *Logger) Record(e Entry) {
+	if l == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.enc.Encode(e); err != nil {
+		log.Printf("❌ Failed to write audit entry: %v", err)
+	}
+}
+
+// ParseProxies parses the comma-separated addresses or CIDRs of the authenticating proxies, i.e. 10.0.0.0/8
+func ParseProxies(s string) ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy %q: %w", v, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q: %w", v, err)
+		}
+		proxies = append(proxies, p.Masked())
+	}
+	return proxies, nil
+}
+
+// TrustProxies drops the ActorHeader of requests that do not come straight from one of proxies, so clients cannot
+// name themselves. It runs before middleware rewriting RemoteAddr from forwarding headers, such as RealIP.
+func TrustProxies(proxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(ActorHeader) != "" && !fromProxy(r, proxies) {
+				r.Header.Del(ActorHeader)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func fromProxy(r *http.Request, proxies []netip.Prefix) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range proxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Actor identifies who sent r: the ActorHeader, or the client address when there is no proxy
+func Actor(r *http.Request) string {
+	if u := r.Header.Get(ActorHeader); u != "" {
+		return u
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	l.Record(Entry{Action: "claim.extend", Actor: "dev", Kind: "Storage", Namespace: "dev", Name: "mystorage", Outcome: Allowed})
+	l.Record(Entry{Action: "claim.extend", Actor: "dev", Kind: "Storage", Namespace: "dev", Name: "mystorage", Outcome: Denied})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var e Entry
+	require.NoError(t, json.Unmarshal(lines[1], &e))
+	assert.Equal(t, Denied, e.Outcome)
+	assert.False(t, e.Time.IsZero())
+
+	var nilLogger *Logger
+	nilLogger.Record(Entry{Action: "claim.extend"}) // must not panic
+}
+
+func TestActor(t *testing.T) {
+	r := httptest.NewRequest("POST", "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	assert.Equal(t, "10.0.0.1:1234", Actor(r))
+
+	r.Header.Set(ActorHeader, "alice")
+	assert.Equal(t, "alice", Actor(r))
+}
+
+func TestTrustProxies(t *testing.T) {
+	proxies, err := ParseProxies("10.0.0.0/8, 192.168.1.10")
+	require.NoError(t, err)
+	var user string
+	h := TrustProxies(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = r.Header.Get(ActorHeader)
+	}))
+
+	for remote, expected := range map[string]string{
+		"10.1.2.3:1234":     "alice",
+		"192.168.1.10:1234": "alice",
+		"192.168.1.11:1234": "",
+		"203.0.113.7:1234":  "",
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = remote
+		r.Header.Set(ActorHeader, "alice")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		assert.Equal(t, expected, user, remote)
+	}
+
+	_, err = ParseProxies("10.0.0.0/33")
+	assert.Error(t, err)
+}
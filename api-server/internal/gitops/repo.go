@@ -0,0 +1,151 @@
+// Package gitops commits manifests to a Git working copy that ArgoCD syncs into the cluster. It runs the git
+// binary, so the credential helpers and SSH configuration of the working copy apply to pulls and pushes.
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Committer identifies the api-server on every commit; the user who asked for the change is the author
+var Committer = Author{Name: "platform api-server", Email: "api-server@platform.example.org"}
+
+// Author of a commit
+type Author struct {
+	Name  string
+	Email string
+}
+
+func (a Author) String() string {
+	return fmt.Sprintf("%s <%s>", a.Name, a.Email)
+}
+
+// Repo is a Git working copy. Commits are serialized; with a Remote, every commit is rebased onto the
+// remote branch first and pushed after.
+type Repo struct {
+	Dir    string
+	Remote string // i.e. origin, empty to only commit locally
+	Branch string
+
+	mu sync.Mutex
+}
+
+// Open checks dir is a Git working copy
+func Open(ctx context.Context, dir, remote, branch string) (*Repo, error) {
+	r := &Repo{Dir: dir, Remote: remote, Branch: branch}
+	if _, err := r.git(ctx, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, fmt.Errorf("%s is not a Git working copy: %w", dir, err)
+	}
+	return r, nil
+}
+
+// Read returns a file of the working copy, an error wrapping fs.ErrNotExist when it is missing
+func (r *Repo) Read(path string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(r.Dir, filepath.FromSlash(path)))
+}
+
+// List returns the paths of the .yaml files under dir, relative to the working copy
+func (r *Repo) List(dir string) ([]string, error) {
+	var paths []string
+	root := filepath.Join(r.Dir, filepath.FromSlash(dir))
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(p, ".yaml") {
+			rel, err := filepath.Rel(r.Dir, p)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return paths, err
+}
+
+// Commit applies the changes returned by change, a nil content deleting the file, and commits them.
+// change runs after the working copy caught up with the remote, so reads inside it see the latest manifests.
+// It returns the commit, or the current HEAD when nothing changed.
+func (r *Repo) Commit(ctx context.Context, author Author, message string, change func() (map[string][]byte, error)) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Remote != "" {
+		if _, err := r.git(ctx, "pull", "--rebase", "--quiet", r.Remote, r.Branch); err != nil {
+			return "", err
+		}
+	}
+
+	files, err := change()
+	if err != nil {
+		return "", err
+	}
+	paths := make([]string, 0, len(files))
+	for path, content := range files {
+		full := filepath.Join(r.Dir, filepath.FromSlash(path))
+		if content == nil {
+			if err := os.Remove(full); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+		} else {
+			if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+				return "", err
+			}
+			if err := os.WriteFile(full, content, 0o644); err != nil {
+				return "", err
+			}
+		}
+		paths = append(paths, path)
+	}
+	if _, err := r.git(ctx, append([]string{"add", "--all", "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	if _, err := r.git(ctx, "diff", "--cached", "--quiet"); err == nil {
+		return r.Head(ctx) // nothing staged
+	}
+	if _, err := r.git(ctx, "commit", "--quiet", "--author", author.String(), "-m", message); err != nil {
+		return "", err
+	}
+
+	if r.Remote != "" {
+		if _, err := r.git(ctx, "push", "--quiet", r.Remote, "HEAD:"+r.Branch); err != nil {
+			return "", err
+		}
+	}
+	return r.Head(ctx)
+}
+
+// Head is the current commit
+func (r *Repo) Head(ctx context.Context) (string, error) {
+	return r.git(ctx, "rev-parse", "HEAD")
+}
+
+// LastCommit is the latest commit that changed path, empty when it was never committed
+func (r *Repo) LastCommit(ctx context.Context, path string) (string, error) {
+	return r.git(ctx, "log", "-1", "--format=%H", "--", path)
+}
+
+func (r *Repo) git(ctx context.Context, args ...string) (string, error) {
+	args = append([]string{"-c", "user.name=" + Committer.Name, "-c", "user.email=" + Committer.Email}, args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.Dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[4], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
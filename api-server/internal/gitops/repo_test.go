@@ -0,0 +1,99 @@
+package gitops
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.org"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// newRemote returns a bare repository with one commit on main, standing in for the GitOps remote
+func newRemote(t *testing.T) string {
+	t.Helper()
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	run(t, t.TempDir(), "init", "--quiet", "--bare", "--initial-branch=main", remote)
+	seed := filepath.Join(t.TempDir(), "seed")
+	run(t, t.TempDir(), "clone", "--quiet", remote, seed)
+	run(t, seed, "commit", "--quiet", "--allow-empty", "-m", "init")
+	run(t, seed, "push", "--quiet", "origin", "HEAD:main")
+	return remote
+}
+
+func clone(t *testing.T, remote string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "work")
+	run(t, t.TempDir(), "clone", "--quiet", "--branch", "main", remote, dir)
+	return dir
+}
+
+func TestCommit_PushesWithAuthor(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemote(t)
+	repo, err := Open(ctx, clone(t, remote), "origin", "main")
+	require.NoError(t, err)
+
+	alice := Author{Name: "alice", Email: "alice@example.org"}
+	sha, err := repo.Commit(ctx, alice, "create dev/storage/a", func() (map[string][]byte, error) {
+		return map[string][]byte{"tenants/dev/storage/a.yaml": []byte("kind: Storage\n")}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sha, run(t, remote, "rev-parse", "main"))
+	assert.Equal(t, "alice <alice@example.org>", run(t, remote, "log", "-1", "--format=%an <%ae>", "main"))
+	assert.Equal(t, Committer.Name, run(t, remote, "log", "-1", "--format=%cn", "main"))
+
+	paths, err := repo.List("tenants")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tenants/dev/storage/a.yaml"}, paths)
+	last, err := repo.LastCommit(ctx, "tenants/dev/storage/a.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, sha, last)
+
+	// nothing to commit returns HEAD
+	same, err := repo.Commit(ctx, alice, "noop", func() (map[string][]byte, error) {
+		return map[string][]byte{"tenants/dev/storage/a.yaml": []byte("kind: Storage\n")}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sha, same)
+
+	_, err = repo.Commit(ctx, alice, "delete dev/storage/a", func() (map[string][]byte, error) {
+		return map[string][]byte{"tenants/dev/storage/a.yaml": nil}, nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, run(t, remote, "ls-tree", "-r", "--name-only", "main"))
+}
+
+func TestCommit_RebasesOntoRemote(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemote(t)
+	repo, err := Open(ctx, clone(t, remote), "origin", "main")
+	require.NoError(t, err)
+
+	// another replica commits first
+	other := clone(t, remote)
+	run(t, other, "commit", "--quiet", "--allow-empty", "-m", "elsewhere")
+	run(t, other, "push", "--quiet", "origin", "HEAD:main")
+
+	_, err = repo.Commit(ctx, Author{Name: "bob", Email: "bob@example.org"}, "create", func() (map[string][]byte, error) {
+		return map[string][]byte{"b.yaml": []byte("b\n")}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "create\nelsewhere\ninit", run(t, remote, "log", "--format=%s", "main"))
+}
+
+func TestOpen_NotAWorkingCopy(t *testing.T) {
+	_, err := Open(context.Background(), t.TempDir(), "", "main")
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// LastActivityAnnotation is when a claim was last used (RFC3339): viewed, extended or its credentials revealed
+	// through the api-server, or bumped by its workloads. The claim-controller deletes claims idle for longer than
+	// their idle timeout.
+	LastActivityAnnotation = "platform.example.org/last-activity"
+	// activityResolution throttles the activity recorded by views, which would otherwise patch a claim on every
+	// page load
+	activityResolution = time.Minute
+)
+
+// TouchClaim records activity on a claim in LastActivityAnnotation
+func (k *KubeClient) TouchClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) error {
+	patch, err := json.Marshal(map[string]any{"metadata": map[string]any{"annotations": map[string]string{
+		LastActivityAnnotation: time.Now().UTC().Format(time.RFC3339),
+	}}})
+	if err != nil {
+		return err
+	}
+	_, err = k.DynamicClient.Resource(gvr).Namespace(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// touchClaim records activity on a claim without failing the request; cv is the claim when it was just read,
+// whose recent activity is not recorded again
+func (h *Handler) touchClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, cv *ClaimView) {
+	if cv != nil && time.Since(cv.LastActivity) < activityResolution {
+		return
+	}
+	if err := h.TouchClaim(ctx, ns, gvr, name); err != nil {
+		log.Printf("❌ Failed to record activity on claim %s/%s: %v", ns, name, err)
+	}
+}
+
+// ActivityAPI records activity on a claim, the heartbeat of workloads using it so it is not deleted as idle
+func (h *Handler) ActivityAPI(w http.ResponseWriter, r *http.Request) {
+	ns, gvr, name, ok := h.claimTarget(w, r)
+	if !ok {
+		return
+	}
+	if err := h.TouchClaim(r.Context(), ns, gvr, name); err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
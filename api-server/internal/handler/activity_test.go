@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"api-server/internal/audit"
+	"api-server/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestClaimActivity(t *testing.T) {
+	claimer := &FakeClaimer{
+		GVRs: map[Resource]schema.GroupVersion{"storage": {Group: "platform.example.org", Version: "v1alpha1"}},
+		Claims: []ClaimView{
+			{Name: "idle", Namespace: "dev", Location: "US", LastActivity: time.Now().Add(-time.Hour)},
+			{Name: "busy", Namespace: "dev", Location: "US", LastActivity: time.Now().Add(-10 * time.Second)},
+		},
+		Connection: map[string][]byte{"password": []byte("secret")},
+	}
+	h := &Handler{Claimer: claimer, Metrics: metrics.InitPrometheus()}
+	r := chi.NewRouter()
+	r.Get("/api/v1/claims/{type}/{namespace}/{name}", h.GetClaimAPI)
+	r.Post("/api/v1/claims/{type}/{namespace}/{name}/extend", h.ExtendClaimAPI)
+	r.Get("/api/v1/claims/{type}/{namespace}/{name}/connection", h.ConnectionAPI)
+	r.Post("/api/v1/claims/{type}/{namespace}/{name}/activity", h.ActivityAPI)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		code          int
+		expectTouched bool
+	}{
+		{"view", http.MethodGet, "/api/v1/claims/storage/dev/idle", "", http.StatusOK, true},
+		{"view of a claim active within a minute", http.MethodGet, "/api/v1/claims/storage/dev/busy", "", http.StatusOK, false},
+		{"extend", http.MethodPost, "/api/v1/claims/storage/dev/busy/extend", `{"duration":"1h"}`, http.StatusOK, true},
+		{"masked connection details", http.MethodGet, "/api/v1/claims/storage/dev/idle/connection", "", http.StatusOK, false},
+		{"revealed connection details", http.MethodGet, "/api/v1/claims/storage/dev/busy/connection?reveal=true", "", http.StatusOK, true},
+		{"heartbeat", http.MethodPost, "/api/v1/claims/storage/dev/busy/activity", "", http.StatusNoContent, true},
+		{"heartbeat of a missing claim", http.MethodPost, "/api/v1/claims/storage/dev/missing/activity", "", http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claimer.Touched = nil
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(audit.ActorHeader, "dev")
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			require.Equal(t, tt.code, rr.Code, rr.Body.String())
+			assert.Equal(t, tt.expectTouched, len(claimer.Touched) == 1, claimer.Touched)
+		})
+	}
+}
+
+func TestNewClaimView_LastActivity(t *testing.T) {
+	u := &unstructured.Unstructured{}
+	u.SetAnnotations(map[string]string{LastActivityAnnotation: "2026-10-18T09:30:00Z"})
+	assert.Equal(t, time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC), newClaimView(u).LastActivity)
+
+	assert.True(t, newClaimView(&unstructured.Unstructured{}).LastActivity.IsZero())
+}
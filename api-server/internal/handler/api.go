@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"api-server/internal/audit"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ClaimRequest is the JSON body accepted by the claims API
+type ClaimRequest struct {
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Region    string            `json:"region"`
+	TTL       string            `json:"ttl,omitempty"`     // Go duration within the kind's bounds, i.e. 4h
+	Tags      map[string]string `json:"tags,omitempty"`    // cost-allocation tags, see TagKeys
+	Cluster   string            `json:"cluster,omitempty"` // target cluster, see /api/v1/clusters; empty for the default
+}
+
+// APIError is the JSON body returned by the claims API on failure
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// CreateClaimAPI is the JSON equivalent of SubmitHandler
+func (h *Handler) CreateClaimAPI(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var req ClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	c, err := h.newClaim(string(h.ResourceOf(req.Type)), req.Name, req.Namespace, req.Region, req.TTL)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if c.Tags, err = h.TagPolicy.Resolve(req.Tags); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	c.Cluster = req.Cluster
+
+	if err := h.submitClaim(r.Context(), c, start); err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, createdView(c))
+}
+
+// createdView is the claim returned by the create and clone APIs, before Crossplane reports any status
+func createdView(c *Claim) ClaimView {
+	return ClaimView{
+		Name:      c.Name,
+		Kind:      c.Kind(),
+		Location:  c.Region,
+		Namespace: c.Namespace,
+		Status:    "Unknown",
+		TTL:       c.TTL.String(),
+		Tags:      c.Tags,
+		Cluster:   c.Cluster,
+	}
+}
+
+// ListClaimsAPI is the JSON equivalent of GetClaims
+func (h *Handler) ListClaimsAPI(w http.ResponseWriter, r *http.Request) {
+	ns := r.URL.Query().Get("ns")
+	if ns == "" {
+		writeError(w, http.StatusBadRequest, "query parameter ns is required")
+		return
+	}
+	rs := string(h.ResourceOf(r.URL.Query().Get("type")))
+	gv := h.VerifyGVR(Resource(rs))
+	if gv == nil {
+		writeError(w, http.StatusBadRequest, "resource "+rs+" not found in supported GVRs")
+		return
+	}
+
+	cv, err := h.ListClaims(r.Context(), ns, schema.GroupVersionResource{
+		Group:    gv.Group,
+		Version:  gv.Version,
+		Resource: rs,
+	})
+	if err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, cv)
+}
+
+// UpdateClaimRequest is the JSON body accepted when editing a claim
+type UpdateClaimRequest struct {
+	Region string `json:"region"`
+}
+
+// ExtendClaimRequest is the JSON body accepted when extending the TTL of a claim
+type ExtendClaimRequest struct {
+	Duration string `json:"duration"` // Go duration, i.e. 2h30m
+}
+
+// GetClaimAPI returns a single claim, including its conditions
+func (h *Handler) GetClaimAPI(w http.ResponseWriter, r *http.Request) {
+	ns, gvr, name, ok := h.claimTarget(w, r)
+	if !ok {
+		return
+	}
+	cv, err := h.GetClaim(r.Context(), ns, gvr, name)
+	if err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	h.touchClaim(r.Context(), ns, gvr, name, cv)
+	writeJSON(w, http.StatusOK, cv)
+}
+
+// UpdateClaimAPI edits the user-editable fields of a claim
+func (h *Handler) UpdateClaimAPI(w http.ResponseWriter, r *http.Request) {
+	ns, gvr, name, ok := h.claimTarget(w, r)
+	if !ok {
+		return
+	}
+	var req UpdateClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if err := checkRegion(req.Region); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	cv, err := h.UpdateClaim(r.Context(), ns, gvr, name, ClaimUpdate{Region: req.Region})
+	if err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, cv)
+}
+
+// ExtendClaimAPI pushes the expiry of a claim forward, within the renewals and maximum lifetime of its kind
+func (h *Handler) ExtendClaimAPI(w http.ResponseWriter, r *http.Request) {
+	ns, gvr, name, ok := h.claimTarget(w, r)
+	if !ok {
+		return
+	}
+	var req ExtendClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	by, err := time.ParseDuration(req.Duration)
+	if err != nil || by <= 0 {
+		writeError(w, http.StatusBadRequest, "duration must be a positive Go duration, i.e. 1h")
+		return
+	}
+	cv, err := h.extendClaim(r, ns, gvr, name, by)
+	if err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, cv)
+}
+
+// extendClaim extends a claim and records the attempt in the audit trail
+func (h *Handler) extendClaim(r *http.Request, ns string, gvr schema.GroupVersionResource, name string, by time.Duration) (*ClaimView, error) {
+	cv, err := h.ExtendClaim(r.Context(), ns, gvr, name, by, h.ttlBounds(gvr.Resource))
+	entry := audit.Entry{
+		Action:    "claim.extend",
+		Actor:     audit.Actor(r),
+		Kind:      gvr.Resource,
+		Namespace: ns,
+		Name:      name,
+		Outcome:   audit.Allowed,
+		Details:   map[string]string{"by": by.String()},
+	}
+	switch {
+	case errors.Is(err, ErrExtendLimit):
+		entry.Outcome = audit.Denied
+		entry.Details["reason"] = err.Error()
+	case err != nil:
+		return nil, err
+	default:
+		entry.Details["ttl"] = cv.TTL
+		entry.Details["renewals"] = strconv.Itoa(cv.Renewals)
+		h.touchClaim(r.Context(), ns, gvr, name, nil)
+	}
+	h.Audit.Record(entry)
+	return cv, err
+}
+
+// DeleteClaimAPI deletes a claim
+func (h *Handler) DeleteClaimAPI(w http.ResponseWriter, r *http.Request) {
+	ns, gvr, name, ok := h.claimTarget(w, r)
+	if !ok {
+		return
+	}
+	if err := h.DeleteClaim(r.Context(), ns, gvr, name); err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// claimTarget resolves the /api/v1/claims/{type}/{namespace}/{name} URL parameters
+func (h *Handler) claimTarget(w http.ResponseWriter, r *http.Request) (string, schema.GroupVersionResource, string, bool) {
+	rs := string(h.ResourceOf(chi.URLParam(r, "type")))
+	gv := h.VerifyGVR(Resource(rs))
+	if gv == nil {
+		writeError(w, http.StatusNotFound, "resource "+rs+" not found in supported GVRs")
+		return "", schema.GroupVersionResource{}, "", false
+	}
+	gvr := schema.GroupVersionResource{
+		Group:    gv.Group,
+		Version:  gv.Version,
+		Resource: rs,
+	}
+	return chi.URLParam(r, "namespace"), gvr, chi.URLParam(r, "name"), true
+}
+
+// statusFor maps Kubernetes API errors onto the HTTP status returned to API clients
+func statusFor(err error) int {
+	if errors.Is(err, ErrExtendLimit) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, ErrUnknownCluster) {
+		return http.StatusBadRequest
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		if code := int(status.Status().Code); code != 0 {
+			return code
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("❌ Failed to encode response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, APIError{Code: code, Message: msg})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// TTLAnnotation holds the lifetime of a claim as a Go duration, i.e. 4h
+	TTLAnnotation = "platform.example.org/ttl"
+	// RenewalsAnnotation counts how many times a claim was extended
+	RenewalsAnnotation = "platform.example.org/renewals"
+	// MaxLifetimeAnnotation caps TTLAnnotation in the claim-controller, recorded when a claim is extended
+	MaxLifetimeAnnotation = "platform.example.org/max-lifetime"
+	// ExpiresAtAnnotation is when the claim-controller deletes the claim (RFC3339), which it records from the TTL,
+	// lifecycle policy, idle timeout and grace period of the claim
+	ExpiresAtAnnotation = "platform.example.org/expires-at"
+	// DefaultTTL matches claim-controller TTLSeconds for claims without TTLAnnotation
+	DefaultTTL = 10 * time.Minute
+)
+
+// expiresAt is the deletion time the claim-controller recorded on a claim, zero until it did or when the claim
+// is exempted from expiry
+func expiresAt(u *unstructured.Unstructured) time.Time {
+	t, _ := time.Parse(time.RFC3339, u.GetAnnotations()[ExpiresAtAnnotation])
+	return t
+}
+
+// GetClaim returns a single claim
+func (k *KubeClient) GetClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) (*ClaimView, error) {
+	u, err := k.DynamicClient.Resource(gvr).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	cv := newClaimView(u)
+	return &cv, nil
+}
+
+// UpdateClaim applies the user-editable fields of u to the existing claim
+func (k *KubeClient) UpdateClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, u ClaimUpdate) (*ClaimView, error) {
+	return k.mutateClaim(ctx, ns, gvr, name, updateFields(u))
+}
+
+// ExtendClaim pushes the expiry of a claim forward by adding to its TTLAnnotation, within the renewals
+// and maximum lifetime of bounds
+func (k *KubeClient) ExtendClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, by time.Duration, bounds TTLBounds) (*ClaimView, error) {
+	return k.mutateClaim(ctx, ns, gvr, name, extendFields(by, bounds))
+}
+
+func updateFields(update ClaimUpdate) func(*unstructured.Unstructured) error {
+	return func(u *unstructured.Unstructured) error {
+		if err := unstructured.SetNestedField(u.Object, update.Region, "spec", "location"); err != nil {
+			return fmt.Errorf("error setting spec.location: %w", err)
+		}
+		return nil
+	}
+}
+
+func extendFields(by time.Duration, bounds TTLBounds) func(*unstructured.Unstructured) error {
+	return func(u *unstructured.Unstructured) error {
+		annotations := u.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		ttl := DefaultTTL
+		if v, ok := annotations[TTLAnnotation]; ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s annotation %q: %w", TTLAnnotation, v, err)
+			}
+			ttl = d
+		}
+		renewals := 0
+		if v, ok := annotations[RenewalsAnnotation]; ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s annotation %q: %w", RenewalsAnnotation, v, err)
+			}
+			renewals = n
+		}
+
+		ttl, err := bounds.Extend(ttl, renewals, by)
+		if err != nil {
+			return err
+		}
+		annotations[TTLAnnotation] = ttl.String()
+		annotations[RenewalsAnnotation] = strconv.Itoa(renewals + 1)
+		annotations[MaxLifetimeAnnotation] = bounds.MaxLifetime.String()
+		delete(annotations, ExpiresAtAnnotation) // stale until the claim-controller records the extended one
+		u.SetAnnotations(annotations)
+		return nil
+	}
+}
+
+// DeleteClaim deletes a claim; Crossplane then deletes the composite and managed resources
+func (k *KubeClient) DeleteClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) error {
+	if err := k.DynamicClient.Resource(gvr).Namespace(ns).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		log.Printf("❌ Failed to delete claim: %v", err)
+		return err
+	}
+	return nil
+}
+
+// mutateClaim re-fetches and updates a claim, retrying on conflicts with the claim-controller
+func (k *KubeClient) mutateClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, mutate func(*unstructured.Unstructured) error) (*ClaimView, error) {
+	var updated *unstructured.Unstructured
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		u, err := k.DynamicClient.Resource(gvr).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if err := mutate(u); err != nil {
+			return err
+		}
+		updated, err = k.DynamicClient.Resource(gvr).Namespace(ns).Update(ctx, u, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		log.Printf("❌ Failed to update claim: %v", err)
+		return nil, err
+	}
+	cv := newClaimView(updated)
+	return &cv, nil
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestKubeClient_Expiry(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	claim := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{"location": "EU"}}}
+	claim.SetGroupVersionKind(storageGVR.GroupVersion().WithKind("Storage"))
+	claim.SetNamespace("alice")
+	claim.SetName("bucket")
+	claim.SetCreationTimestamp(metav1.NewTime(created))
+	// a lifecycle policy gave the claim 8h, not the 1h it requested
+	claim.SetAnnotations(map[string]string{TTLAnnotation: "1h", ExpiresAtAnnotation: created.Add(8 * time.Hour).Format(time.RFC3339)})
+	k := &KubeClient{
+		DynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			storageGVR: "StorageList",
+		}),
+		GVRs: map[Resource]schema.GroupVersion{"storage": storageGVR.GroupVersion()},
+	}
+
+	_, err := k.DynamicClient.Resource(storageGVR).Namespace("alice").Create(ctx, claim, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	cv, err := k.GetClaim(ctx, "alice", storageGVR, "bucket")
+	require.NoError(t, err)
+	assert.True(t, created.Add(8*time.Hour).Equal(cv.ExpiresAt), "the expiry recorded by the claim-controller")
+
+	cv, err = k.UpdateClaim(ctx, "alice", storageGVR, "bucket", ClaimUpdate{Region: "US"})
+	require.NoError(t, err)
+	assert.Equal(t, "US", cv.Location)
+	assert.Equal(t, "1h", cv.TTL, "editing a claim leaves its lifetime alone")
+	assert.True(t, created.Add(8*time.Hour).Equal(cv.ExpiresAt))
+
+	cv, err = k.ExtendClaim(ctx, "alice", storageGVR, "bucket", time.Hour, DefaultTTLBounds)
+	require.NoError(t, err)
+	assert.Equal(t, "2h0m0s", cv.TTL)
+	assert.True(t, cv.ExpiresAt.IsZero(), "unknown until the claim-controller records the extended expiry")
+}
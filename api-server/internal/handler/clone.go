@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CloneClaimRequest is the JSON body accepted when cloning a claim; empty fields are copied from the source
+type CloneClaimRequest struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Region    string            `json:"region,omitempty"`
+	TTL       string            `json:"ttl,omitempty"`     // the source's TTL when it is within the kind's bounds, else the default
+	Tags      map[string]string `json:"tags,omitempty"`    // merged over the source's tags
+	Cluster   string            `json:"cluster,omitempty"` // i.e. promotes a claim from dev to staging
+}
+
+// CloneClaimAPI submits a copy of a live claim under a new name, validated like any new claim
+func (h *Handler) CloneClaimAPI(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ns, gvr, name, ok := h.claimTarget(w, r)
+	if !ok {
+		return
+	}
+	var req CloneClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	c, status, err := h.cloneClaim(r.Context(), ns, gvr, name, req)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	if err := h.submitClaim(r.Context(), c, start); err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, createdView(c))
+}
+
+// CloneHandler handles the Clone form of the detail page, then lists the claims of the target namespace
+func (h *Handler) CloneHandler(w http.ResponseWriter, r *http.Request, name string) {
+	start := time.Now()
+	ns := r.FormValue("ns")
+	rs := string(h.ResourceOf(r.FormValue("type")))
+	gv := h.VerifyGVR(Resource(rs))
+	if ns == "" || gv == nil {
+		http.Error(w, "fields ns and type are required", http.StatusBadRequest)
+		return
+	}
+	// the cluster field selects the source claim, see SelectCluster
+	req := CloneClaimRequest{Name: r.FormValue("name"), Namespace: r.FormValue("namespace"), Region: r.FormValue("region"), Cluster: r.FormValue("to-cluster")}
+
+	c, status, err := h.cloneClaim(r.Context(), ns, gv.WithResource(rs), name, req)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if err := h.submitClaim(r.Context(), c, start); err != nil {
+		http.Error(w, err.Error(), statusFor(err))
+		return
+	}
+	http.Redirect(w, r, "/claims?ns="+url.QueryEscape(c.Namespace)+"&type="+rs, http.StatusFound)
+}
+
+// cloneClaim reads the source claim and validates the copy as a new submission. Only user-chosen fields are
+// copied: region, TTL, tags and cluster; server-populated metadata, annotations and status are left behind.
+func (h *Handler) cloneClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, req CloneClaimRequest) (*Claim, int, error) {
+	src, err := h.GetClaim(ctx, ns, gvr, name)
+	if err != nil {
+		return nil, statusFor(err), fmt.Errorf("error reading the claim to clone: %w", err)
+	}
+
+	if req.Namespace == "" {
+		req.Namespace = src.Namespace
+	}
+	if req.Region == "" {
+		req.Region = src.Location
+	}
+	if req.TTL == "" {
+		if _, err := h.ttlBounds(gvr.Resource).Resolve(src.TTL); err == nil {
+			req.TTL = src.TTL
+		}
+	}
+	if req.Cluster == "" {
+		req.Cluster = src.Cluster
+	}
+	if req.Namespace == src.Namespace && strings.ToLower(req.Name) == src.Name && req.Cluster == src.Cluster {
+		return nil, http.StatusBadRequest, fmt.Errorf("Invalid claim name: the clone needs a name other than %s", src.Name)
+	}
+
+	c, err := h.newClaim(gvr.Resource, req.Name, req.Namespace, req.Region, req.TTL)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	c.Cluster = req.Cluster
+	tags := maps.Clone(src.Tags)
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	maps.Copy(tags, req.Tags)
+	if c.Tags, err = h.TagPolicy.Resolve(tags); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return c, http.StatusOK, nil
+}
@@ -0,0 +1,368 @@
+package handler
+
+import (
+	"api-server/internal/health"
+	"api-server/internal/metrics"
+	"api-server/internal/notify"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ErrUnknownCluster is returned when a request targets a cluster the api-server has no connection to
+var ErrUnknownCluster = errors.New("unknown cluster")
+
+// ClusterConfig is a named cluster connection, read from the CLUSTERS environment variable
+type ClusterConfig struct {
+	Name        string `json:"name"`
+	Environment string `json:"environment,omitempty"` // shown to users next to the name, i.e. staging
+	// Kubeconfig is the path of a kubeconfig file, i.e. mounted from a Secret; empty uses in-cluster config,
+	// falling back to ~/.kube/config
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	Context    string `json:"context,omitempty"` // kubeconfig context, empty for its current context
+}
+
+// ParseClusters reads the cluster connections from JSON, the first one being the default:
+//
+//	[{"name": "dev", "environment": "dev"}, {"name": "prod", "kubeconfig": "/etc/platform/clusters/prod", "context": "prod"}]
+func ParseClusters(s string) ([]ClusterConfig, error) {
+	var cfgs []ClusterConfig
+	if err := json.Unmarshal([]byte(s), &cfgs); err != nil {
+		return nil, fmt.Errorf("invalid clusters: %w", err)
+	}
+	if len(cfgs) == 0 {
+		return nil, fmt.Errorf("invalid clusters: at least one cluster is required")
+	}
+	seen := map[string]bool{}
+	for _, cfg := range cfgs {
+		if !validDNSName.MatchString(cfg.Name) {
+			return nil, fmt.Errorf("invalid clusters: name %q must match [a-z0-9]([-a-z0-9]*[a-z0-9])?", cfg.Name)
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("invalid clusters: duplicate name %q", cfg.Name)
+		}
+		seen[cfg.Name] = true
+	}
+	return cfgs, nil
+}
+
+// NewClusterClient builds a client for cfg, see NewKubernetesClient when it names no kubeconfig or context
+func NewClusterClient(cfg ClusterConfig) (*KubeClient, error) {
+	if cfg.Kubeconfig == "" && cfg.Context == "" {
+		return NewKubernetesClient()
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = cfg.Kubeconfig
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: cfg.Context}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kubeconfig of cluster %s: %w", cfg.Name, err)
+	}
+	return newKubeClient(config)
+}
+
+// Cluster is a connection to one of the clusters claims can target
+type Cluster struct {
+	*KubeClient
+	Name        string
+	Environment string
+	Checks      []health.Check // reported by /api/v1/clusters
+}
+
+// WatchClaimEvents publishes the claim events of the cluster, see KubeClient.WatchClaimEvents, naming the cluster
+func (c *Cluster) WatchClaimEvents(ctx context.Context, publish func(notify.Event), expiringWithin time.Duration) error {
+	return c.KubeClient.WatchClaimEvents(ctx, func(ev notify.Event) {
+		ev.Claim.Cluster = c.Name
+		publish(ev)
+	}, expiringWithin)
+}
+
+// ClusterStatus is the health of a cluster as returned by /api/v1/clusters
+type ClusterStatus struct {
+	Name        string   `json:"name"`
+	Environment string   `json:"environment,omitempty"`
+	Default     bool     `json:"default"`
+	Healthy     bool     `json:"healthy"`
+	Failures    []string `json:"failures,omitempty"`
+}
+
+type clusterKey struct{}
+
+// WithCluster targets the claim operations run with ctx at a cluster, the default one when name is empty
+func WithCluster(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, clusterKey{}, name)
+}
+
+// ClusterFrom is the cluster set by WithCluster
+func ClusterFrom(ctx context.Context) string {
+	name, _ := ctx.Value(clusterKey{}).(string)
+	return name
+}
+
+// SelectCluster is a middleware reading the target cluster from the cluster query parameter or form field
+func SelectCluster(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name := r.FormValue("cluster"); name != "" {
+			r = r.WithContext(WithCluster(r.Context(), name))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Clusters is a Claimer spreading claims over several clusters. Operations on one claim go to the cluster of
+// their context (see WithCluster), or to the default cluster; listings without a cluster span every cluster.
+type Clusters struct {
+	clusters []*Cluster // the first one is the default
+}
+
+// NewClusters groups connected clusters, the first one being the default
+func NewClusters(clusters ...*Cluster) *Clusters {
+	return &Clusters{clusters: clusters}
+}
+
+// Default is the cluster of requests that name none, the api-server's own cluster in most setups
+func (m *Clusters) Default() *Cluster {
+	return m.clusters[0]
+}
+
+// All lists the clusters, the default first
+func (m *Clusters) All() []*Cluster {
+	return slices.Clone(m.clusters)
+}
+
+func (m *Clusters) target(ctx context.Context) (*Cluster, error) {
+	return m.byName(ClusterFrom(ctx))
+}
+
+func (m *Clusters) byName(name string) (*Cluster, error) {
+	if name == "" {
+		return m.Default(), nil
+	}
+	for _, c := range m.clusters {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	names := make([]string, 0, len(m.clusters))
+	for _, c := range m.clusters {
+		names = append(names, c.Name)
+	}
+	return nil, fmt.Errorf("%w %q: must be one of %v", ErrUnknownCluster, name, names)
+}
+
+// CreateClaim creates c in c.Cluster, or in the cluster of ctx when it is empty
+func (m *Clusters) CreateClaim(ctx context.Context, c *Claim) error {
+	name := c.Cluster
+	if name == "" {
+		name = ClusterFrom(ctx)
+	}
+	cl, err := m.byName(name)
+	if err != nil {
+		return err
+	}
+	c.Cluster = cl.Name
+	return cl.CreateClaim(ctx, c)
+}
+
+func (m *Clusters) GetClaims(w http.ResponseWriter, r *http.Request) {
+	renderClaims(w, r, m)
+}
+
+// ListClaims lists the claims of the cluster of ctx, or of every cluster when ctx names none. A cluster that
+// cannot be reached is left out of the listing, which only fails when no cluster answered.
+func (m *Clusters) ListClaims(ctx context.Context, ns string, gvr schema.GroupVersionResource) ([]ClaimView, error) {
+	clusters := m.clusters
+	if name := ClusterFrom(ctx); name != "" {
+		cl, err := m.byName(name)
+		if err != nil {
+			return nil, err
+		}
+		clusters = []*Cluster{cl}
+	}
+
+	lists := make([][]ClaimView, len(clusters))
+	errs := make([]error, len(clusters))
+	var wg sync.WaitGroup
+	for i, cl := range clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lists[i], errs[i] = cl.ListClaims(ctx, ns, gvr)
+		}()
+	}
+	wg.Wait()
+
+	var cvs []ClaimView
+	answered := false
+	for i, cl := range clusters {
+		if errs[i] != nil {
+			log.Printf("❌ Failed to list claims of cluster %s: %v", cl.Name, errs[i])
+			errs[i] = fmt.Errorf("cluster %s: %w", cl.Name, errs[i])
+			continue
+		}
+		answered = true
+		cvs = append(cvs, inCluster(cl.Name, lists[i])...)
+	}
+	if !answered {
+		return nil, errors.Join(errs...)
+	}
+	return cvs, nil
+}
+
+func (m *Clusters) GetClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) (*ClaimView, error) {
+	cl, err := m.target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return inClusterView(cl.Name)(cl.GetClaim(ctx, ns, gvr, name))
+}
+
+func (m *Clusters) UpdateClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, u ClaimUpdate) (*ClaimView, error) {
+	cl, err := m.target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return inClusterView(cl.Name)(cl.UpdateClaim(ctx, ns, gvr, name, u))
+}
+
+func (m *Clusters) ExtendClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, by time.Duration, bounds TTLBounds) (*ClaimView, error) {
+	cl, err := m.target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return inClusterView(cl.Name)(cl.ExtendClaim(ctx, ns, gvr, name, by, bounds))
+}
+
+func (m *Clusters) DeleteClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) error {
+	cl, err := m.target(ctx)
+	if err != nil {
+		return err
+	}
+	return cl.DeleteClaim(ctx, ns, gvr, name)
+}
+
+func (m *Clusters) ConnectionDetails(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) (map[string][]byte, error) {
+	cl, err := m.target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cl.ConnectionDetails(ctx, ns, gvr, name)
+}
+
+func (m *Clusters) TouchClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) error {
+	cl, err := m.target(ctx)
+	if err != nil {
+		return err
+	}
+	return cl.TouchClaim(ctx, ns, gvr, name)
+}
+
+// CachedClaims lists the claims of every cluster whose caches are synced
+func (m *Clusters) CachedClaims(ns string) ([]ClaimView, error) {
+	var cvs []ClaimView
+	var errs []error
+	for _, cl := range m.clusters {
+		list, err := cl.CachedClaims(ns)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", cl.Name, err))
+			continue
+		}
+		cvs = append(cvs, inCluster(cl.Name, list)...)
+	}
+	if len(errs) == len(m.clusters) {
+		return nil, errors.Join(errs...)
+	}
+	return cvs, nil
+}
+
+// Inventory lists the claims of every cluster for the inventory and cost metrics
+func (m *Clusters) Inventory() ([]metrics.InventoryItem, error) {
+	cvs, err := m.CachedClaims("")
+	if err != nil {
+		return nil, err
+	}
+	items := make([]metrics.InventoryItem, 0, len(cvs))
+	for _, cv := range cvs {
+		items = append(items, inventoryItem(cv.Kind, cv))
+	}
+	return items, nil
+}
+
+// VerifyGVR checks the kind against the default cluster, every cluster serving the same claim kinds
+func (m *Clusters) VerifyGVR(r Resource) *schema.GroupVersion {
+	return m.Default().VerifyGVR(r)
+}
+
+func (m *Clusters) KindOf(r Resource) string {
+	return m.Default().KindOf(r)
+}
+
+func (m *Clusters) ResourceOf(t string) Resource {
+	return m.Default().ResourceOf(t)
+}
+
+// Health runs the checks of every cluster concurrently, each bounded by timeout
+func (m *Clusters) Health(ctx context.Context, timeout time.Duration) []ClusterStatus {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	statuses := make([]ClusterStatus, len(m.clusters))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, cl := range m.clusters {
+		statuses[i] = ClusterStatus{Name: cl.Name, Environment: cl.Environment, Default: i == 0, Healthy: true}
+		for _, check := range cl.Checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := check.Fn(ctx)
+				if err == nil {
+					return
+				}
+				log.Printf("❌ Cluster %s check %s failed: %v", cl.Name, check.Name, err)
+				mu.Lock()
+				defer mu.Unlock()
+				statuses[i].Healthy = false
+				statuses[i].Failures = append(statuses[i].Failures, fmt.Sprintf("%s: %v", check.Name, err))
+			}()
+		}
+	}
+	wg.Wait()
+	for i := range statuses {
+		slices.Sort(statuses[i].Failures)
+	}
+	return statuses
+}
+
+func inCluster(name string, cvs []ClaimView) []ClaimView {
+	for i := range cvs {
+		cvs[i].Cluster = name
+	}
+	return cvs
+}
+
+func inClusterView(name string) func(*ClaimView, error) (*ClaimView, error) {
+	return func(cv *ClaimView, err error) (*ClaimView, error) {
+		if cv != nil {
+			cv.Cluster = name
+		}
+		return cv, err
+	}
+}
+
+// ClustersAPI lists the clusters claims can target with their health, empty with a single cluster
+func (h *Handler) ClustersAPI(w http.ResponseWriter, r *http.Request) {
+	if h.Clusters == nil {
+		writeJSON(w, http.StatusOK, []ClusterStatus{})
+		return
+	}
+	writeJSON(w, http.StatusOK, h.Clusters.Health(r.Context(), 5*time.Second))
+}
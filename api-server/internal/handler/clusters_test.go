@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"api-server/internal/health"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func storageClaim(ns, name, region string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{"location": region}}}
+	u.SetGroupVersionKind(storageGVR.GroupVersion().WithKind("Storage"))
+	u.SetNamespace(ns)
+	u.SetName(name)
+	return u
+}
+
+// fakeCluster is a cluster backed by a fake dynamic client holding claims
+func fakeCluster(name string, claims ...*unstructured.Unstructured) *Cluster {
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		storageGVR: "StorageList",
+	})
+	// created through the storage resource, the fake client would guess storages from the kind
+	for _, u := range claims {
+		if _, err := dyn.Resource(storageGVR).Namespace(u.GetNamespace()).Create(context.Background(), u, metav1.CreateOptions{}); err != nil {
+			panic(err)
+		}
+	}
+	kc := &KubeClient{DynamicClient: dyn, GVRs: map[Resource]schema.GroupVersion{"storage": storageGVR.GroupVersion()}}
+	return &Cluster{KubeClient: kc, Name: name}
+}
+
+// unreachable fails every request to the cluster
+func unreachable(cl *Cluster) *Cluster {
+	cl.DynamicClient.(*dynamicfake.FakeDynamicClient).PrependReactor("*", "*", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	return cl
+}
+
+func TestParseClusters(t *testing.T) {
+	cfgs, err := ParseClusters(`[{"name": "dev"}, {"name": "prod", "environment": "production", "kubeconfig": "/etc/platform/clusters/prod", "context": "prod"}]`)
+	require.NoError(t, err)
+	assert.Equal(t, []ClusterConfig{
+		{Name: "dev"},
+		{Name: "prod", Environment: "production", Kubeconfig: "/etc/platform/clusters/prod", Context: "prod"},
+	}, cfgs)
+
+	for _, s := range []string{`[]`, `[{"name": "Prod"}]`, `[{"name": "dev"}, {"name": "dev"}]`, `{"name": "dev"}`} {
+		_, err := ParseClusters(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestClusters_ListClaims(t *testing.T) {
+	ctx := context.Background()
+	clusters := NewClusters(
+		fakeCluster("dev", storageClaim("alice", "a", "US")),
+		fakeCluster("staging", storageClaim("alice", "b", "EU"), storageClaim("bob", "c", "EU")),
+	)
+
+	cvs, err := clusters.ListClaims(ctx, "alice", storageGVR)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dev/a", "staging/b"}, clusterNames(cvs))
+
+	cvs, err = clusters.ListClaims(WithCluster(ctx, "staging"), "alice", storageGVR)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"staging/b"}, clusterNames(cvs))
+
+	_, err = clusters.ListClaims(WithCluster(ctx, "prod"), "alice", storageGVR)
+	assert.ErrorIs(t, err, ErrUnknownCluster)
+	assert.Equal(t, http.StatusBadRequest, statusFor(err))
+}
+
+func TestClusters_ListClaims_Unreachable(t *testing.T) {
+	ctx := context.Background()
+	partial := NewClusters(fakeCluster("dev", storageClaim("alice", "a", "US")), unreachable(fakeCluster("staging")))
+	cvs, err := partial.ListClaims(ctx, "alice", storageGVR)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev/a"}, clusterNames(cvs))
+
+	down := NewClusters(unreachable(fakeCluster("dev")), unreachable(fakeCluster("staging")))
+	_, err = down.ListClaims(ctx, "alice", storageGVR)
+	assert.ErrorContains(t, err, "cluster dev: connection refused")
+	assert.ErrorContains(t, err, "cluster staging: connection refused")
+}
+
+func TestClusters_Target(t *testing.T) {
+	ctx := context.Background()
+	clusters := NewClusters(
+		fakeCluster("dev", storageClaim("alice", "a", "US")),
+		fakeCluster("staging", storageClaim("alice", "a", "EU")),
+	)
+
+	cv, err := clusters.GetClaim(ctx, "alice", storageGVR, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", cv.Cluster, "no cluster targets the default one")
+	assert.Equal(t, "US", cv.Location)
+
+	staging := WithCluster(ctx, "staging")
+	cv, err = clusters.GetClaim(staging, "alice", storageGVR, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "staging", cv.Cluster)
+	assert.Equal(t, "EU", cv.Location)
+
+	cv, err = clusters.UpdateClaim(staging, "alice", storageGVR, "a", ClaimUpdate{Region: "US"})
+	require.NoError(t, err)
+	assert.Equal(t, "staging", cv.Cluster)
+	assert.Equal(t, "US", cv.Location)
+
+	require.NoError(t, clusters.DeleteClaim(staging, "alice", storageGVR, "a"))
+	_, err = clusters.GetClaim(staging, "alice", storageGVR, "a")
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = clusters.GetClaim(ctx, "alice", storageGVR, "a")
+	assert.NoError(t, err, "the claim of the default cluster is left alone")
+
+	err = clusters.CreateClaim(ctx, &Claim{Name: "b", GVR: storageGVR, Namespace: "alice", Region: "US", Cluster: "prod"})
+	assert.ErrorIs(t, err, ErrUnknownCluster)
+}
+
+func TestClusters_Health(t *testing.T) {
+	dev := fakeCluster("dev")
+	dev.Checks = []health.Check{health.Ping}
+	staging := fakeCluster("staging")
+	staging.Environment = "staging"
+	staging.Checks = []health.Check{health.Ping, {Name: "kube-apiserver", Fn: func(context.Context) error { return errors.New("timeout") }}}
+
+	h := &Handler{Clusters: NewClusters(dev, staging)}
+	rr := httptest.NewRecorder()
+	h.ClustersAPI(rr, httptest.NewRequest(http.MethodGet, "/api/v1/clusters", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[
+		{"name": "dev", "default": true, "healthy": true},
+		{"name": "staging", "environment": "staging", "default": false, "healthy": false, "failures": ["kube-apiserver: timeout"]}
+	]`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	(&Handler{}).ClustersAPI(rr, httptest.NewRequest(http.MethodGet, "/api/v1/clusters", nil))
+	assert.JSONEq(t, `[]`, rr.Body.String())
+}
+
+func TestSelectCluster(t *testing.T) {
+	clusters := NewClusters(fakeCluster("dev"), fakeCluster("staging", storageClaim("alice", "a", "EU")))
+	h := &Handler{Claimer: clusters, Clusters: clusters}
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/claims?ns=alice&type=storage&cluster=staging", nil)
+	rr := httptest.NewRecorder()
+	SelectCluster(http.HandlerFunc(h.ListClaimsAPI)).ServeHTTP(rr, r)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"cluster":"staging"`)
+}
+
+func clusterNames(cvs []ClaimView) []string {
+	names := make([]string, 0, len(cvs))
+	for _, cv := range cvs {
+		names = append(names, cv.Cluster+"/"+cv.Name)
+	}
+	return names
+}
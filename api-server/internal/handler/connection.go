@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"api-server/internal/audit"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// ConnectionSecretSuffix names the Secret Crossplane writes the connection details of a claim to, i.e. mystorage-connection
+	ConnectionSecretSuffix = "-connection"
+	// Masked replaces credentials until they are revealed
+	Masked = "********"
+)
+
+// publicConnectionKeys are published by the compositions and shown without revealing, every other key is a credential
+var publicConnectionKeys = []string{"region", "bucket", "bucketArn", "tableName", "tableArn", "instanceId", "publicIp", "privateIp"}
+
+// ConnectionDetail is one key of the connection Secret of a claim
+type ConnectionDetail struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"` // Masked for credentials unless revealed
+	Secret bool   `json:"secret"`
+}
+
+// Connection is returned by ConnectionAPI
+type Connection struct {
+	Kind      string             `json:"kind"`
+	Namespace string             `json:"namespace"`
+	Name      string             `json:"name"`
+	Revealed  bool               `json:"revealed"`
+	Details   []ConnectionDetail `json:"details"`
+	Env       string             `json:"env,omitempty"` // shell exports of every detail, only when revealed
+}
+
+// ConnectionDetails reads the Secret the claim's writeConnectionSecretToRef points to
+func (k *KubeClient) ConnectionDetails(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) (map[string][]byte, error) {
+	u, err := k.DynamicClient.Resource(gvr).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	secretName, _, _ := unstructured.NestedString(u.Object, "spec", "writeConnectionSecretToRef", "name")
+	if secretName == "" {
+		secretName = name + ConnectionSecretSuffix
+	}
+	secret, err := k.Clientset.CoreV1().Secrets(ns).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("connection details are published once the claim is Ready: %w", err)
+	}
+	return secret.Data, nil
+}
+
+// ConnectionAPI returns the connection details of a claim to its owner, credentials masked unless ?reveal=true.
+// Reveals are written to the audit trail.
+func (h *Handler) ConnectionAPI(w http.ResponseWriter, r *http.Request) {
+	ns, gvr, name, ok := h.claimTarget(w, r)
+	if !ok {
+		return
+	}
+	reveal := r.URL.Query().Get("reveal") == "true"
+
+	owner := isOwner(r, ns)
+	if reveal {
+		entry := audit.Entry{Action: "claim.connection.reveal", Actor: audit.Actor(r), Kind: gvr.Resource, Namespace: ns, Name: name, Outcome: audit.Allowed}
+		if !owner {
+			entry.Outcome = audit.Denied
+		}
+		h.Audit.Record(entry)
+	}
+	if !owner {
+		writeError(w, http.StatusForbidden, "connection details are only shown to the owner of namespace "+ns)
+		return
+	}
+
+	data, err := h.ConnectionDetails(r.Context(), ns, gvr, name)
+	if err != nil {
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	if reveal {
+		// credentials are revealed to be used
+		h.touchClaim(r.Context(), ns, gvr, name, nil)
+	}
+	writeJSON(w, http.StatusOK, newConnection(gvr.Resource, ns, name, data, reveal))
+}
+
+func newConnection(rs, ns, name string, data map[string][]byte, reveal bool) Connection {
+	conn := Connection{Kind: rs, Namespace: ns, Name: name, Revealed: reveal, Details: []ConnectionDetail{}}
+	var env strings.Builder
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		d := ConnectionDetail{Key: k, Value: string(data[k]), Secret: !slices.Contains(publicConnectionKeys, k)}
+		if reveal {
+			fmt.Fprintf(&env, "export %s=%s\n", envName(rs, k), shellQuote(d.Value))
+		} else if d.Secret {
+			d.Value = Masked
+		}
+		conn.Details = append(conn.Details, d)
+	}
+	conn.Env = env.String()
+	return conn
+}
+
+// isOwner reports whether the authenticated user owns ns, users' namespaces being named after them. Anonymous
+// callers own nothing: the ActorHeader is only kept on requests from the authenticating proxies.
+func isOwner(r *http.Request, ns string) bool {
+	user := r.Header.Get(audit.ActorHeader)
+	return user != "" && user == ns
+}
+
+// envName is the variable a connection key is exported as, i.e. storage, tableArn -> STORAGE_TABLE_ARN
+func envName(rs, key string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(rs))
+	b.WriteByte('_')
+	prev := rune(0)
+	for _, c := range key {
+		switch {
+		case unicode.IsUpper(c) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			b.WriteByte('_')
+			b.WriteRune(c)
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			b.WriteRune(unicode.ToUpper(c))
+		default:
+			b.WriteByte('_')
+		}
+		prev = c
+	}
+	return b.String()
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
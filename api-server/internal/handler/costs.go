@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"api-server/internal/pricing"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ClaimCost is the rate and accrued cost of a live claim
+type ClaimCost struct {
+	Kind      string  `json:"kind"`
+	Namespace string  `json:"namespace"`
+	Name      string  `json:"name"`
+	Region    string  `json:"region"`
+	Preset    string  `json:"preset,omitempty"`
+	Hourly    float64 `json:"hourly"`
+	Accrued   float64 `json:"accrued"`
+}
+
+// NamespaceCost sums the claims of a namespace
+type NamespaceCost struct {
+	Namespace string  `json:"namespace"`
+	Claims    int     `json:"claims"`
+	Hourly    float64 `json:"hourly"`
+	Accrued   float64 `json:"accrued"`
+}
+
+// CostReport is returned by CostsAPI
+type CostReport struct {
+	Currency   string          `json:"currency"`
+	Claims     []ClaimCost     `json:"claims"`
+	Namespaces []NamespaceCost `json:"namespaces"`
+	Unpriced   int             `json:"unpriced"` // claims without a rate in the pricing table
+}
+
+// EstimateAPI prices a claim before it is submitted: ?type=storage&region=US[&preset=...][&ttl=4h],
+// the ttl defaulting to the kind's default lifetime
+func (h *Handler) EstimateAPI(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	rs := string(h.ResourceOf(q.Get("type")))
+	if h.VerifyGVR(Resource(rs)) == nil {
+		writeError(w, http.StatusBadRequest, "resource "+rs+" not found in supported GVRs")
+		return
+	}
+	ttl, err := h.ttlBounds(rs).Resolve(q.Get("ttl"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	est, err := h.Pricing.Estimate(h.KindOf(Resource(rs)), q.Get("preset"), q.Get("region"), ttl)
+	if err != nil {
+		writeError(w, pricingStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, est)
+}
+
+// CostsAPI reports the accrued cost of every live claim, optionally in one ?namespace=, and per namespace
+func (h *Handler) CostsAPI(w http.ResponseWriter, r *http.Request) {
+	cvs, err := h.CachedClaims(r.URL.Query().Get("namespace"))
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, h.costReport(cvs, time.Now()))
+}
+
+func (h *Handler) costReport(cvs []ClaimView, now time.Time) CostReport {
+	report := CostReport{Currency: h.Pricing.Currency, Claims: []ClaimCost{}, Namespaces: []NamespaceCost{}}
+	byNamespace := map[string]*NamespaceCost{}
+
+	for _, cv := range cvs {
+		hourly, err := h.Pricing.Hourly(cv.Kind, cv.Preset, cv.Location)
+		if err != nil {
+			report.Unpriced++
+			continue
+		}
+		cc := ClaimCost{
+			Kind:      cv.Kind,
+			Namespace: cv.Namespace,
+			Name:      cv.Name,
+			Region:    cv.Location,
+			Preset:    cv.Preset,
+			Hourly:    hourly,
+			Accrued:   pricing.Accrued(hourly, cv.CreatedAt, now),
+		}
+		report.Claims = append(report.Claims, cc)
+
+		nc, ok := byNamespace[cv.Namespace]
+		if !ok {
+			nc = &NamespaceCost{Namespace: cv.Namespace}
+			byNamespace[cv.Namespace] = nc
+		}
+		nc.Claims++
+		nc.Hourly = pricing.Round(nc.Hourly + cc.Hourly)
+		nc.Accrued = pricing.Round(nc.Accrued + cc.Accrued)
+	}
+
+	for _, nc := range byNamespace {
+		report.Namespaces = append(report.Namespaces, *nc)
+	}
+	slices.SortFunc(report.Namespaces, func(a, b NamespaceCost) int { return strings.Compare(a.Namespace, b.Namespace) })
+	slices.SortFunc(report.Claims, func(a, b ClaimCost) int {
+		return strings.Compare(a.Namespace+"/"+a.Kind+"/"+a.Name, b.Namespace+"/"+b.Kind+"/"+b.Name)
+	})
+	return report
+}
+
+// claimEstimate prices an existing claim over its TTL for the detail page, nil when it has no rate
+func (h *Handler) claimEstimate(cv *ClaimView) *pricing.Estimate {
+	ttl := DefaultTTL
+	if d, err := time.ParseDuration(cv.TTL); err == nil {
+		ttl = d
+	}
+	est, err := h.Pricing.Estimate(cv.Kind, cv.Preset, cv.Location, ttl)
+	if err != nil {
+		return nil
+	}
+	return &est
+}
+
+func pricingStatus(err error) int {
+	if errors.Is(err, pricing.ErrNoRate) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"api-server/internal/notify"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+)
+
+// expiryScanInterval is how often the caches are scanned for claims about to expire
+const expiryScanInterval = time.Minute
+
+// WatchClaimEvents publishes the lifecycle events of every claim observed by the informers: created,
+// ready, failed and deleted as they happen, expiring once a claim is within expiringWithin of its expiry.
+// Claims already in the cluster when the api-server starts do not produce created events.
+func (k *KubeClient) WatchClaimEvents(ctx context.Context, publish func(notify.Event), expiringWithin time.Duration) error {
+	for _, gvr := range k.ClaimGVRs() {
+		kind := k.KindOf(Resource(gvr.Resource))
+		view := func(obj any) (ClaimView, bool) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return ClaimView{}, false
+			}
+			cv := newClaimView(u)
+			cv.Kind = kind
+			return cv, true
+		}
+
+		_, err := k.Informers.ForResource(gvr).Informer().AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+			AddFunc: func(obj any, isInInitialList bool) {
+				if cv, ok := view(obj); ok && !isInInitialList {
+					publish(notify.NewEvent(notify.Created, notifyClaim(cv), ""))
+				}
+			},
+			UpdateFunc: func(oldObj, newObj any) {
+				old, ok := view(oldObj)
+				cur, ok2 := view(newObj)
+				if !ok || !ok2 {
+					return
+				}
+				for _, t := range claimTransitions(old, cur) {
+					publish(notify.NewEvent(t, notifyClaim(cur), conditionMessage(cur)))
+				}
+			},
+			DeleteFunc: func(obj any) {
+				if cv, ok := view(obj); ok {
+					publish(notify.NewEvent(notify.Deleted, notifyClaim(cv), ""))
+				}
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("error watching %s: %w", gvr.GroupResource(), err)
+		}
+	}
+
+	go k.watchExpiring(ctx, publish, expiringWithin)
+	return nil
+}
+
+// watchExpiring warns once per expiry time, so extending a claim re-arms the warning
+func (k *KubeClient) watchExpiring(ctx context.Context, publish func(notify.Event), within time.Duration) {
+	warned := map[string]time.Time{}
+	ticker := time.NewTicker(expiryScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cvs, err := k.CachedClaims("")
+		if err != nil {
+			log.Printf("❌ Skipping expiry scan: %v", err)
+			continue
+		}
+		warned = expiringClaims(cvs, warned, time.Now(), within, publish)
+	}
+}
+
+// expiringClaims publishes claim.expiring for the claims expiring within the window that were not warned
+// about for the same expiry yet, and returns the warned set pruned of claims that no longer exist
+func expiringClaims(cvs []ClaimView, warned map[string]time.Time, now time.Time, within time.Duration, publish func(notify.Event)) map[string]time.Time {
+	next := make(map[string]time.Time, len(warned))
+	for _, cv := range cvs {
+		key := cv.Namespace + "/" + cv.Kind + "/" + cv.Name
+		if w, ok := warned[key]; ok && w.Equal(cv.ExpiresAt) {
+			next[key] = w
+			continue
+		}
+		if left := cv.ExpiresAt.Sub(now); left > 0 && left <= within {
+			publish(notify.NewEvent(notify.Expiring, notifyClaim(cv), fmt.Sprintf("expires in %s", left.Round(time.Second))))
+			next[key] = cv.ExpiresAt
+		}
+	}
+	return next
+}
+
+// claimTransitions derives the lifecycle events between two observations of a claim
+func claimTransitions(old, cur ClaimView) []notify.EventType {
+	var events []notify.EventType
+	if old.Status != "Ready" && cur.Status == "Ready" {
+		events = append(events, notify.Ready)
+	}
+	// a claim Crossplane cannot reconcile, or one that was Ready and no longer is, has failed
+	if (syncFailed(cur) && !syncFailed(old)) || (old.Status == "Ready" && cur.Status == "NotReady") {
+		events = append(events, notify.Failed)
+	}
+	return events
+}
+
+func syncFailed(cv ClaimView) bool {
+	for _, c := range cv.Conditions {
+		if c.Type == "Synced" && c.Status == "False" {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionMessage explains a transition with the message of the first unhealthy condition
+func conditionMessage(cv ClaimView) string {
+	for _, c := range cv.Conditions {
+		if c.Status == "False" && c.Message != "" {
+			return c.Message
+		}
+	}
+	return ""
+}
+
+func notifyClaim(cv ClaimView) notify.Claim {
+	return notify.Claim{
+		Kind:      cv.Kind,
+		Namespace: cv.Namespace,
+		Name:      cv.Name,
+		Region:    cv.Location,
+		Status:    cv.Status,
+		ExpiresAt: cv.ExpiresAt,
+	}
+}
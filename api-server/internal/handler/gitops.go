@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"api-server/internal/gitops"
+	"api-server/internal/notify"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"net/http"
+	"path"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// Sync states of a claim submitted through Git
+const (
+	SyncPending   = "Pending"   // committed, not in the cluster yet
+	SyncSynced    = "Synced"    // the cluster matches the manifest
+	SyncOutOfSync = "OutOfSync" // the cluster differs from the manifest
+	SyncPruning   = "Pruning"   // removed from Git, still in the cluster
+	SyncNotInGit  = "NotInGit"  // created outside Git
+)
+
+// GitOpsStatus reports how far a claim committed to Git got into the cluster
+type GitOpsStatus struct {
+	Path     string `json:"path"`
+	Revision string `json:"revision,omitempty"` // last commit that changed the manifest
+	Sync     string `json:"sync"`
+}
+
+// GitClaimer submits claims as manifests committed to a Git repository that ArgoCD syncs, instead of writing
+// them to the Kubernetes API. Reads still come from the cluster, merged with the manifests not synced yet.
+type GitClaimer struct {
+	*KubeClient
+	Repo *gitops.Repo
+	// Root is the directory of the manifests in the repository, laid out as <Root>/<namespace>/<resource>/<name>.yaml
+	Root string
+	// EmailDomain makes the commit author <namespace>@EmailDomain, namespaces being named after their users
+	EmailDomain string
+}
+
+func (g *GitClaimer) path(ns, rs, name string) string {
+	return path.Join(g.Root, ns, rs, name+".yaml")
+}
+
+func (g *GitClaimer) author(ns string) gitops.Author {
+	return gitops.Author{Name: ns, Email: ns + "@" + g.EmailDomain}
+}
+
+// CreateClaim commits the manifest of c
+func (g *GitClaimer) CreateClaim(ctx context.Context, c *Claim) error {
+	obj, err := c.object()
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return fmt.Errorf("error rendering the claim: %w", err)
+	}
+	p := g.path(c.Namespace, c.GVR.Resource, c.Name)
+	gr := c.GVR.GroupResource()
+
+	_, err = g.Repo.Commit(ctx, g.author(c.Namespace), fmt.Sprintf("Create %s %s/%s", c.Kind(), c.Namespace, c.Name), func() (map[string][]byte, error) {
+		if _, err := g.Repo.Read(p); err == nil {
+			return nil, apierrors.NewAlreadyExists(gr, c.Name)
+		}
+		if _, err := g.KubeClient.GetClaim(ctx, c.Namespace, c.GVR, c.Name); err == nil {
+			return nil, apierrors.NewAlreadyExists(gr, c.Name)
+		}
+		return map[string][]byte{p: b}, nil
+	})
+	if err != nil {
+		log.Printf("❌ Failed to commit claim: %v", err)
+		return fmt.Errorf("error committing the claim: %w", err)
+	}
+	log.Printf("✅ Committed %s %s/%s to %s", c.Kind(), c.Namespace, c.Name, p)
+	return nil
+}
+
+// UpdateClaim commits the user-editable fields of u to the manifest of a claim
+func (g *GitClaimer) UpdateClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, u ClaimUpdate) (*ClaimView, error) {
+	return g.mutateManifest(ctx, ns, gvr, name, "Update", updateFields(u))
+}
+
+// ExtendClaim commits the extended TTL of a claim to its manifest
+func (g *GitClaimer) ExtendClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, by time.Duration, bounds TTLBounds) (*ClaimView, error) {
+	return g.mutateManifest(ctx, ns, gvr, name, "Extend", extendFields(by, bounds))
+}
+
+// TouchClaim records activity on the live claim rather than in its manifest: LastActivityAnnotation is runtime
+// state bumped by every view and workload heartbeat, which would flood the repository with commits. The
+// tenant-claims ArgoCD Application ignores the annotation (argocd/tenant-claims.yaml), so it neither shows the
+// claim OutOfSync nor is reverted by a sync.
+func (g *GitClaimer) TouchClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) error {
+	return g.KubeClient.TouchClaim(ctx, ns, gvr, name)
+}
+
+// DeleteClaim removes the manifest of a claim; ArgoCD then prunes the claim
+func (g *GitClaimer) DeleteClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) error {
+	p := g.path(ns, gvr.Resource, name)
+	_, err := g.Repo.Commit(ctx, g.author(ns), fmt.Sprintf("Delete %s %s/%s", gvr.Resource, ns, name), func() (map[string][]byte, error) {
+		if _, err := g.Repo.Read(p); err != nil {
+			return nil, g.notInGit(gvr, name, err)
+		}
+		return map[string][]byte{p: nil}, nil
+	})
+	if err != nil {
+		log.Printf("❌ Failed to delete claim: %v", err)
+	}
+	return err
+}
+
+func (g *GitClaimer) mutateManifest(ctx context.Context, ns string, gvr schema.GroupVersionResource, name, verb string, mutate func(*unstructured.Unstructured) error) (*ClaimView, error) {
+	p := g.path(ns, gvr.Resource, name)
+	_, err := g.Repo.Commit(ctx, g.author(ns), fmt.Sprintf("%s %s %s/%s", verb, gvr.Resource, ns, name), func() (map[string][]byte, error) {
+		u, err := g.manifest(p)
+		if err != nil {
+			return nil, g.notInGit(gvr, name, err)
+		}
+		if err := mutate(u); err != nil {
+			return nil, err
+		}
+		b, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{p: b}, nil
+	})
+	if err != nil {
+		log.Printf("❌ Failed to update claim: %v", err)
+		return nil, err
+	}
+	return g.GetClaim(ctx, ns, gvr, name)
+}
+
+func (g *GitClaimer) notInGit(gvr schema.GroupVersionResource, name string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return apierrors.NewNotFound(gvr.GroupResource(), name)
+	}
+	return err
+}
+
+func (g *GitClaimer) manifest(p string) (*unstructured.Unstructured, error) {
+	b, err := g.Repo.Read(p)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(b, &u.Object); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", p, err)
+	}
+	return u, nil
+}
+
+// GetClaim returns the claim from the cluster, or its manifest while it is not synced, with its GitOps status
+func (g *GitClaimer) GetClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) (*ClaimView, error) {
+	live, err := g.KubeClient.GetClaim(ctx, ns, gvr, name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	p := g.path(ns, gvr.Resource, name)
+	manifest, mErr := g.manifest(p)
+	if mErr != nil && !errors.Is(mErr, fs.ErrNotExist) {
+		return nil, mErr
+	}
+	if live == nil && manifest == nil {
+		return nil, err
+	}
+	cv := g.view(ctx, p, manifest, live)
+	return &cv, nil
+}
+
+// ListClaims lists the claims of the cluster and the manifests not synced yet
+func (g *GitClaimer) ListClaims(ctx context.Context, ns string, gvr schema.GroupVersionResource) ([]ClaimView, error) {
+	live, err := g.KubeClient.ListClaims(ctx, ns, gvr)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := g.Repo.List(path.Join(g.Root, ns, gvr.Resource))
+	if err != nil {
+		return nil, err
+	}
+	manifests := make(map[string]*unstructured.Unstructured, len(paths))
+	for _, p := range paths {
+		u, err := g.manifest(p)
+		if err != nil {
+			return nil, err
+		}
+		manifests[u.GetName()] = u
+	}
+
+	cvs := make([]ClaimView, 0, len(live)+len(manifests))
+	for i := range live {
+		cvs = append(cvs, g.view(ctx, g.path(ns, gvr.Resource, live[i].Name), manifests[live[i].Name], &live[i]))
+		delete(manifests, live[i].Name)
+	}
+	for name, u := range manifests {
+		cvs = append(cvs, g.view(ctx, g.path(ns, gvr.Resource, name), u, nil))
+	}
+	return cvs, nil
+}
+
+func (g *GitClaimer) GetClaims(w http.ResponseWriter, r *http.Request) {
+	renderClaims(w, r, g)
+}
+
+// view merges the manifest and live claim, either may be nil
+func (g *GitClaimer) view(ctx context.Context, p string, manifest *unstructured.Unstructured, live *ClaimView) ClaimView {
+	status := &GitOpsStatus{Path: p}
+	revision, err := g.Repo.LastCommit(ctx, p)
+	if err != nil {
+		log.Printf("❌ Failed to read the revision of %s: %v", p, err)
+	}
+	status.Revision = revision
+
+	var cv ClaimView
+	switch {
+	case live == nil:
+		cv = newClaimView(manifest)
+		cv.ExpiresAt = time.Time{} // the claim-controller starts the TTL once the claim exists
+		status.Sync = SyncPending
+	case manifest == nil:
+		cv = *live
+		status.Sync = SyncNotInGit
+		if revision != "" {
+			status.Sync = SyncPruning
+		}
+	default:
+		cv = *live
+		desired := newClaimView(manifest)
+		status.Sync = SyncOutOfSync
+		if desired.Location == live.Location && desired.TTL == live.TTL && maps.Equal(desired.Tags, live.Tags) {
+			status.Sync = SyncSynced
+		}
+	}
+	cv.GitOps = status
+	return cv
+}
+
+// PruneExpired wraps publish to remove the manifest of every claim deleted in the cluster, i.e. by the
+// claim-controller once its TTL elapsed, so ArgoCD does not recreate it. The manifest is found from the resource
+// the event's claim kind is served as, i.e. modeldeploymentclaims for ModelDeploymentClaim.
+func (g *GitClaimer) PruneExpired(ctx context.Context, publish func(notify.Event)) func(notify.Event) {
+	return func(ev notify.Event) {
+		publish(ev)
+		if ev.Type != notify.Deleted {
+			return
+		}
+		go func() {
+			ns, rs, name := ev.Claim.Namespace, string(g.ResourceOf(ev.Claim.Kind)), ev.Claim.Name
+			p := g.path(ns, rs, name)
+			_, err := g.Repo.Commit(ctx, gitops.Committer, fmt.Sprintf("Prune %s %s/%s deleted from the cluster", rs, ns, name), func() (map[string][]byte, error) {
+				if _, err := g.Repo.Read(p); err != nil {
+					return nil, nil // already removed, i.e. by DeleteClaim
+				}
+				return map[string][]byte{p: nil}, nil
+			})
+			if err != nil {
+				log.Printf("❌ Failed to prune %s: %v", p, err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"api-server/internal/gitops"
+	"api-server/internal/notify"
+	"context"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/yaml"
+)
+
+var storageGVR = schema.GroupVersionResource{Group: "platform.example.org", Version: "v1alpha1", Resource: "storage"}
+
+func gitLog(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// newGitClaimer commits into a local repository with no remote, over an empty fake cluster
+func newGitClaimer(t *testing.T) (*GitClaimer, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "gitops")
+	gitLog(t, t.TempDir(), "init", "--quiet", "--initial-branch=main", dir)
+	repo, err := gitops.Open(context.Background(), dir, "", "main")
+	require.NoError(t, err)
+
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		storageGVR: "StorageList",
+	})
+	kc := &KubeClient{DynamicClient: dyn, GVRs: map[Resource]schema.GroupVersion{"storage": storageGVR.GroupVersion()}}
+	return &GitClaimer{KubeClient: kc, Repo: repo, Root: "tenants", EmailDomain: "example.org"}, dir
+}
+
+func TestGitClaimer_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	g, dir := newGitClaimer(t)
+	c := &Claim{Name: "bucket", GVR: storageGVR, Region: "eu-west-1", Namespace: "alice", TTL: time.Hour,
+		Tags: map[string]string{"team": "data", "project": UnassignedTag, "cost-center": UnassignedTag}}
+	const path = "tenants/alice/storage/bucket.yaml"
+
+	require.NoError(t, g.CreateClaim(ctx, c))
+	assert.Equal(t, "alice <alice@example.org>", gitLog(t, dir, "log", "-1", "--format=%an <%ae>"))
+	assert.True(t, apierrors.IsAlreadyExists(g.CreateClaim(ctx, c)))
+
+	b, err := g.Repo.Read(path)
+	require.NoError(t, err)
+	var manifest unstructured.Unstructured
+	require.NoError(t, yaml.Unmarshal(b, &manifest.Object))
+	assert.Equal(t, "Storage", manifest.GetKind())
+	assert.Equal(t, "1h0m0s", manifest.GetAnnotations()[TTLAnnotation])
+	assert.Equal(t, "data", manifest.GetLabels()[TagLabelPrefix+"team"])
+
+	cv, err := g.GetClaim(ctx, "alice", storageGVR, "bucket")
+	require.NoError(t, err)
+	require.NotNil(t, cv.GitOps)
+	assert.Equal(t, SyncPending, cv.GitOps.Sync)
+	assert.Equal(t, path, cv.GitOps.Path)
+	assert.Equal(t, gitLog(t, dir, "rev-parse", "HEAD"), cv.GitOps.Revision)
+	assert.True(t, cv.ExpiresAt.IsZero())
+
+	// ArgoCD syncs the manifest
+	manifest.SetCreationTimestamp(metav1.Now())
+	_, err = g.DynamicClient.Resource(storageGVR).Namespace("alice").Create(ctx, &manifest, metav1.CreateOptions{})
+	require.NoError(t, err)
+	cvs, err := g.ListClaims(ctx, "alice", storageGVR)
+	require.NoError(t, err)
+	require.Len(t, cvs, 1)
+	assert.Equal(t, SyncSynced, cvs[0].GitOps.Sync)
+
+	// activity is recorded on the live claim without a commit
+	head := gitLog(t, dir, "rev-parse", "HEAD")
+	require.NoError(t, g.TouchClaim(ctx, "alice", storageGVR, "bucket"))
+	assert.Equal(t, head, gitLog(t, dir, "rev-parse", "HEAD"))
+	cv, err = g.GetClaim(ctx, "alice", storageGVR, "bucket")
+	require.NoError(t, err)
+	assert.False(t, cv.LastActivity.IsZero())
+	assert.Equal(t, SyncSynced, cv.GitOps.Sync, "the annotation is not drift")
+
+	cv, err = g.ExtendClaim(ctx, "alice", storageGVR, "bucket", time.Hour, DefaultTTLBounds)
+	require.NoError(t, err)
+	assert.Equal(t, SyncOutOfSync, cv.GitOps.Sync)
+	assert.Equal(t, "Extend storage alice/bucket", gitLog(t, dir, "log", "-1", "--format=%s"))
+
+	require.NoError(t, g.DeleteClaim(ctx, "alice", storageGVR, "bucket"))
+	cv, err = g.GetClaim(ctx, "alice", storageGVR, "bucket")
+	require.NoError(t, err)
+	assert.Equal(t, SyncPruning, cv.GitOps.Sync)
+}
+
+func TestGitClaimer_NotInGit(t *testing.T) {
+	ctx := context.Background()
+	g, _ := newGitClaimer(t)
+
+	_, err := g.UpdateClaim(ctx, "alice", storageGVR, "missing", ClaimUpdate{Region: "eu-west-1"})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.True(t, apierrors.IsNotFound(g.DeleteClaim(ctx, "alice", storageGVR, "missing")))
+	_, err = g.GetClaim(ctx, "alice", storageGVR, "missing")
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestGitClaimer_PruneExpired(t *testing.T) {
+	ctx := context.Background()
+	g, dir := newGitClaimer(t)
+	modelGVR := schema.GroupVersionResource{Group: "platform.example.org", Version: "v1alpha1", Resource: "modeldeploymentclaims"}
+	g.GVRs["modeldeploymentclaims"] = modelGVR.GroupVersion()
+	g.Kinds = map[Resource]string{"storage": "Storage", "modeldeploymentclaims": "ModelDeploymentClaim"}
+	const path = "tenants/alice/modeldeploymentclaims/model.yaml"
+
+	require.NoError(t, g.CreateClaim(ctx, &Claim{Name: "model", GVR: modelGVR, kind: "ModelDeploymentClaim", Region: "eu-west-1", Namespace: "alice", TTL: time.Hour}))
+	_, err := g.Repo.Read(path)
+	require.NoError(t, err)
+
+	// the claim-controller deletes the expired claim
+	head := gitLog(t, dir, "rev-parse", "HEAD")
+	var published []notify.Event
+	publish := g.PruneExpired(ctx, func(ev notify.Event) { published = append(published, ev) })
+	publish(notify.NewEvent(notify.Deleted, notify.Claim{Kind: "ModelDeploymentClaim", Namespace: "alice", Name: "model"}, ""))
+	require.Len(t, published, 1)
+	require.Eventually(t, func() bool {
+		rev, err := g.Repo.Head(ctx)
+		return err == nil && rev != head
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "Prune modeldeploymentclaims alice/model deleted from the cluster", gitLog(t, dir, "log", "-1", "--format=%s"))
+	_, err = g.Repo.Read(path)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -1,17 +1,27 @@
 package handler
 
 import (
+	"api-server/internal/audit"
 	"api-server/internal/metrics"
+	"api-server/internal/notify"
+	"api-server/internal/pricing"
+	"api-server/internal/tracing"
 	"context"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
@@ -21,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -29,10 +40,31 @@ import (
 )
 
 type ClaimView struct {
-	Name      string
-	Location  string
-	Namespace string
-	Status    string
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Location  string    `json:"region"`
+	Preset    string    `json:"preset,omitempty"`
+	Namespace string    `json:"namespace"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	TTL       string    `json:"ttl,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+	Renewals  int       `json:"renewals,omitempty"`
+	// LastActivity is when the claim was last used, zero when it never was since its creation
+	LastActivity time.Time         `json:"lastActivity,omitzero"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	Conditions   []ClaimCondition  `json:"conditions,omitempty"`
+	GitOps       *GitOpsStatus     `json:"gitops,omitempty"`  // set when claims are submitted through Git
+	Cluster      string            `json:"cluster,omitempty"` // set when the api-server manages several clusters
+}
+
+// ClaimCondition is a Crossplane status condition (Synced, Ready) of a claim
+type ClaimCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime,omitzero"`
 }
 
 type Claim struct {
@@ -40,12 +72,44 @@ type Claim struct {
 	GVR       schema.GroupVersionResource
 	Region    string
 	Namespace string
+	TTL       time.Duration     // stored in TTLAnnotation, the claim-controller deletes the claim once it elapsed
+	Tags      map[string]string // cost-allocation tags, stored as labels under TagLabelPrefix
+	Cluster   string            // target cluster when the api-server manages several, empty for the default
+	kind      string            // claim kind of GVR, see KindOf
+}
+
+// ClaimUpdate holds the user-editable fields of an existing claim; its lifetime only changes through ExtendClaim
+type ClaimUpdate struct {
+	Region string // stored in spec.location
+}
+
+// Kind is the claim kind served under the resource, i.e. modeldeploymentclaims -> ModelDeploymentClaim
+func (c *Claim) Kind() string {
+	if c.kind != "" {
+		return c.kind
+	}
+	return titleKind(Resource(c.GVR.Resource))
+}
+
+// titleKind is the kind of claims registered without one, the title-cased resource, i.e. storage -> Storage
+func titleKind(r Resource) string {
+	return cases.Title(language.English).String(string(r))
 }
 
 type Claimer interface {
 	CreateClaim(ctx context.Context, c *Claim) error
 	GetClaims(w http.ResponseWriter, r *http.Request)
+	ListClaims(ctx context.Context, ns string, gvr schema.GroupVersionResource) ([]ClaimView, error)
+	GetClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) (*ClaimView, error)
+	UpdateClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, u ClaimUpdate) (*ClaimView, error)
+	ExtendClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, by time.Duration, bounds TTLBounds) (*ClaimView, error)
+	DeleteClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) error
+	ConnectionDetails(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) (map[string][]byte, error)
+	TouchClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) error
+	CachedClaims(ns string) ([]ClaimView, error)
 	VerifyGVR(r Resource) *schema.GroupVersion
+	KindOf(r Resource) string
+	ResourceOf(t string) Resource
 }
 
 type Resource string
@@ -55,43 +119,107 @@ type KubeClient struct {
 	Clientset     kubernetes.Interface
 	Scheme        *runtime.Scheme
 	GVRs          map[Resource]schema.GroupVersion
+	Kinds         map[Resource]string                          // claim kind of each resource in GVRs, see KindOf
+	Informers     dynamicinformer.DynamicSharedInformerFactory // watches every claim in GVRs
 }
 
-// CreateClaim uses client-go to create a Crossplane Claim based on user request
-func (k *KubeClient) CreateClaim(ctx context.Context, c *Claim) error {
+// object is the claim manifest: the user-chosen fields of c, its TTL annotation and tag labels
+func (c *Claim) object() (*unstructured.Unstructured, error) {
 	claim := &unstructured.Unstructured{}
 	claim.SetAPIVersion(fmt.Sprintf("%s/%s", c.GVR.Group, c.GVR.Version))
-	claim.SetKind(cases.Title(language.English).String(c.GVR.Resource))
+	claim.SetKind(c.Kind())
 	claim.SetName(c.Name)
 	claim.SetNamespace(c.Namespace)
+	annotations := map[string]string{}
+	if c.TTL > 0 {
+		annotations[TTLAnnotation] = c.TTL.String()
+	}
+	claim.SetAnnotations(annotations)
+	if len(c.Tags) > 0 {
+		claim.SetLabels(tagLabels(c.Tags))
+	}
 
 	if err := unstructured.SetNestedField(claim.Object, c.Region, "spec", "location"); err != nil {
-		return fmt.Errorf("error setting spec.location: %w", err)
+		return nil, fmt.Errorf("error setting spec.location: %w", err)
+	}
+	// Crossplane writes the connection details published by the composition to this Secret once the claim is Ready
+	if err := unstructured.SetNestedField(claim.Object, c.Name+ConnectionSecretSuffix, "spec", "writeConnectionSecretToRef", "name"); err != nil {
+		return nil, fmt.Errorf("error setting spec.writeConnectionSecretToRef: %w", err)
 	}
+	return claim, nil
+}
 
-	discoveryClient, err := k.Clientset.Discovery().ServerResourcesForGroupVersion(fmt.Sprintf("%s/%s", c.GVR.Group, c.GVR.Version))
+// CreateClaim uses client-go to create a Crossplane Claim based on user request
+func (k *KubeClient) CreateClaim(ctx context.Context, c *Claim) error {
+	ctx, span := tracing.Tracer().Start(ctx, "KubeClient.CreateClaim", trace.WithAttributes(
+		attribute.String("claim.kind", c.Kind()),
+		attribute.String("claim.namespace", c.Namespace),
+		attribute.String("claim.name", c.Name),
+	))
+	defer span.End()
+
+	claim, err := c.object()
 	if err != nil {
-		return fmt.Errorf("❌ Discovery error: %w", err)
+		return err
 	}
-	for _, res := range discoveryClient.APIResources {
-		log.Printf("✅ Discovered: name=%s kind=%s namespaced=%v\n", res.Name, res.Kind, res.Namespaced)
+	// lets the claim-controller and composition functions continue this trace
+	if tp := tracing.Traceparent(ctx); tp != "" {
+		annotations := claim.GetAnnotations()
+		annotations[tracing.TraceparentAnnotation] = tp
+		claim.SetAnnotations(annotations)
 	}
 
-	if _, err := k.DynamicClient.Resource(c.GVR).Namespace(c.Namespace).Create(ctx, claim, metav1.CreateOptions{}); err != nil {
-		log.Printf("❌ Failed to create claim: %v", err)
+	if err := k.discover(ctx, c.GVR.GroupVersion()); err != nil {
+		tracing.RecordError(span, err)
+		return fmt.Errorf("❌ Discovery error: %w", err)
+	}
+
+	createCtx, createSpan := tracing.Tracer().Start(ctx, "create")
+	_, err = k.DynamicClient.Resource(c.GVR).Namespace(c.Namespace).Create(createCtx, claim, metav1.CreateOptions{})
+	createSpan.End()
+	if err != nil {
+		tracing.RecordError(span, err)
+		tracing.Logf(ctx, "❌ Failed to create claim: %v", err)
 		return fmt.Errorf("error creating the claim: %w", err)
 	}
+	tracing.Logf(ctx, "✅ Created %s %s/%s", c.Kind(), c.Namespace, c.Name)
+	return nil
+}
+
+// discover checks the group version is served, using the request context so the call is traced
+func (k *KubeClient) discover(ctx context.Context, gv schema.GroupVersion) error {
+	ctx, span := tracing.Tracer().Start(ctx, "discovery", trace.WithAttributes(attribute.String("k8s.group_version", gv.String())))
+	defer span.End()
+
+	resources := &metav1.APIResourceList{}
+	err := k.Clientset.Discovery().RESTClient().Get().AbsPath("/apis", gv.Group, gv.Version).Do(ctx).Into(resources)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	for _, res := range resources.APIResources {
+		tracing.Logf(ctx, "✅ Discovered: name=%s kind=%s namespaced=%v\n", res.Name, res.Kind, res.Namespaced)
+	}
 	return nil
 }
 
 func (k *KubeClient) GetClaims(w http.ResponseWriter, r *http.Request) {
+	renderClaims(w, r, k)
+}
+
+// renderClaims renders list.html with the claims of ?ns= of kind ?type=
+func renderClaims(w http.ResponseWriter, r *http.Request, c Claimer) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "GetClaims")
+	defer span.End()
+	r = r.WithContext(ctx)
+
 	ns := r.URL.Query().Get("ns")
 	// TODO: Check if namespace exists
 	if ns == "" {
 		ns = "dev-user"
 	}
-	rs := r.URL.Query().Get("type")
-	gv := k.VerifyGVR(Resource(rs))
+	rs := string(c.ResourceOf(r.URL.Query().Get("type")))
+	gv := c.VerifyGVR(Resource(rs))
 	if gv == nil {
 		http.Error(w, fmt.Sprintf("❌ Resource *%v* not found in supported GVRs", rs), http.StatusInternalServerError)
 		return
@@ -101,44 +229,87 @@ func (k *KubeClient) GetClaims(w http.ResponseWriter, r *http.Request) {
 		Version:  gv.Version,
 		Resource: rs,
 	}
-	list, err := k.DynamicClient.
-		Resource(gvr).
-		Namespace(ns).
-		List(r.Context(), metav1.ListOptions{})
+	cv, err := c.ListClaims(r.Context(), ns, gvr)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if err := loadTemplates().ExecuteTemplate(w, "list.html", cv); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
 
-	cv := make([]ClaimView, 0)
+// ListClaims returns a view of every claim of the given resource in the namespace
+func (k *KubeClient) ListClaims(ctx context.Context, ns string, gvr schema.GroupVersionResource) ([]ClaimView, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "KubeClient.ListClaims", trace.WithAttributes(
+		attribute.String("claim.resource", gvr.Resource),
+		attribute.String("claim.namespace", ns),
+	))
+	defer span.End()
 
-	for _, r := range list.Items {
-		name := r.GetName()
-		location, _, _ := unstructured.NestedString(r.Object, "spec", "location")
-		conditions, _, _ := unstructured.NestedSlice(r.Object, "status", "conditions")
-		status := "Unknown"
+	list, err := k.DynamicClient.
+		Resource(gvr).
+		Namespace(ns).
+		List(ctx, metav1.ListOptions{})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("claim.count", len(list.Items)))
+
+	cv := make([]ClaimView, 0, len(list.Items))
+	for _, item := range list.Items {
+		cv = append(cv, newClaimView(&item))
+	}
+	return cv, nil
+}
 
-		for _, cond := range conditions {
-			if condMap, ok := cond.(map[string]any); ok {
-				if condMap["type"] == "Ready" {
-					if _, ok := condMap["status"].(string); ok {
-						status = "Ready"
-						break
-					}
+func newClaimView(u *unstructured.Unstructured) ClaimView {
+	location, _, _ := unstructured.NestedString(u.Object, "spec", "location")
+	preset, _, _ := unstructured.NestedString(u.Object, "spec", "preset")
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	status := "Unknown"
+	cc := make([]ClaimCondition, 0, len(conditions))
+
+	for _, cond := range conditions {
+		if condMap, ok := cond.(map[string]any); ok {
+			c := ClaimCondition{}
+			c.Type, _ = condMap["type"].(string)
+			c.Status, _ = condMap["status"].(string)
+			c.Reason, _ = condMap["reason"].(string)
+			c.Message, _ = condMap["message"].(string)
+			if ts, ok := condMap["lastTransitionTime"].(string); ok {
+				c.LastTransitionTime, _ = time.Parse(time.RFC3339, ts)
+			}
+			cc = append(cc, c)
+
+			if c.Type == "Ready" {
+				switch c.Status {
+				case "True":
+					status = "Ready"
+				case "False":
+					status = "NotReady"
 				}
 			}
 		}
-
-		cv = append(cv, ClaimView{
-			Name:      name,
-			Location:  location,
-			Namespace: ns,
-			Status:    status,
-		})
 	}
-	if err := loadTemplates().ExecuteTemplate(w, "list.html", cv); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+	cv := ClaimView{
+		Name:       u.GetName(),
+		Kind:       u.GetKind(),
+		Location:   location,
+		Preset:     preset,
+		Namespace:  u.GetNamespace(),
+		Status:     status,
+		CreatedAt:  u.GetCreationTimestamp().Time,
+		TTL:        u.GetAnnotations()[TTLAnnotation],
+		Tags:       claimTags(u.GetLabels()),
+		Conditions: cc,
 	}
+	cv.Renewals, _ = strconv.Atoi(u.GetAnnotations()[RenewalsAnnotation])
+	cv.LastActivity, _ = time.Parse(time.RFC3339, u.GetAnnotations()[LastActivityAnnotation])
+	cv.ExpiresAt = expiresAt(u)
+	return cv
 }
 
 func (k *KubeClient) VerifyGVR(r Resource) *schema.GroupVersion {
@@ -150,7 +321,31 @@ func (k *KubeClient) VerifyGVR(r Resource) *schema.GroupVersion {
 	return &gv
 }
 
-func NewKubernetesClient() *KubeClient {
+// KindOf is the claim kind served under a resource, from the claimNames of its XRD
+func (k *KubeClient) KindOf(r Resource) string {
+	if kind, ok := k.Kinds[r]; ok {
+		return kind
+	}
+	return titleKind(r)
+}
+
+// ResourceOf resolves the type of a request, a claim resource or kind in any case (i.e. storage, Storage or
+// ModelDeploymentClaim), to its resource; unknown types are returned lower-cased for VerifyGVR to reject
+func (k *KubeClient) ResourceOf(t string) Resource {
+	rs := Resource(strings.ToLower(t))
+	if _, ok := k.GVRs[rs]; ok {
+		return rs
+	}
+	for r, kind := range k.Kinds {
+		if strings.EqualFold(kind, t) {
+			return r
+		}
+	}
+	return rs
+}
+
+// NewKubernetesClient builds a client from in-cluster config, falling back to ~/.kube/config
+func NewKubernetesClient() (*KubeClient, error) {
 	var config *rest.Config
 	var err error
 
@@ -161,18 +356,24 @@ func NewKubernetesClient() *KubeClient {
 		kubeconfig := filepath.Join(homedir.HomeDir(), ".kube", "config")
 		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
 		if err != nil {
-			log.Fatalf("Unable to load kubeconfig: %v", err)
+			return nil, fmt.Errorf("unable to load kubeconfig: %w", err)
 		}
 	}
+	return newKubeClient(config)
+}
+
+func newKubeClient(config *rest.Config) (*KubeClient, error) {
+	// every client-go request becomes a child span of the HTTP request that caused it
+	config.Wrap(tracing.Transport)
 
 	cs, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Error creating Kubernetes dynamic clientset: %v", err)
+		return nil, fmt.Errorf("error creating Kubernetes clientset: %w", err)
 	}
 
 	c, err := dynamic.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Error creating Kubernetes dynamic client: %v", err)
+		return nil, fmt.Errorf("error creating Kubernetes dynamic client: %w", err)
 	}
 
 	// scheme is only used when decoding Kubernetes runtime objects into Go types (i.e. clientset.AppsV1().Deployments(...))
@@ -184,18 +385,28 @@ func NewKubernetesClient() *KubeClient {
 		DynamicClient: c,
 		Clientset:     cs,
 		Scheme:        runtime.NewScheme(),
+		// extended with the claims of every XRD by DiscoverClaimKinds
 		GVRs: map[Resource]schema.GroupVersion{
 			"storage": { // K8s API
 				Group:   "platform.example.org",
 				Version: "v1alpha1",
 			},
 		},
-	}
+		Kinds:     map[Resource]string{"storage": "Storage"},
+		Informers: dynamicinformer.NewDynamicSharedInformerFactory(c, informerResync),
+	}, nil
 }
 
 type Handler struct {
 	Claimer
-	Metrics *metrics.Metrics
+	Metrics  *metrics.Metrics
+	Pricing  *pricing.Table
+	Notifier *notify.Notifier
+	// TTLBounds limit the lifetime users may request per kind, DefaultTTLBounds apply to the others
+	TTLBounds map[Resource]TTLBounds
+	Audit     *audit.Logger // nil discards the audit trail
+	TagPolicy TagPolicy
+	Clusters  *Clusters // nil with a single cluster, else also the Claimer
 }
 
 func (h *Handler) SubmitHandler(w http.ResponseWriter, r *http.Request) {
@@ -203,67 +414,195 @@ func (h *Handler) SubmitHandler(w http.ResponseWriter, r *http.Request) {
 	// When the form is submitted in the browser via HTML,
 	// the browser encodes the fields into a body like "name=foo&username=bar&type=storage"
 	// and sets Content-Type: application/x-www-form-urlencoded
-	t := strings.ToLower(r.FormValue("type"))
+	t := string(h.ResourceOf(r.FormValue("type")))
 	ns := r.FormValue("username")
 
+	c, err := h.newClaim(t, r.FormValue("name"), ns, r.FormValue("region"), r.FormValue("ttl"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tags := map[string]string{}
+	for _, k := range TagKeys {
+		tags[k] = r.FormValue("tag-" + k)
+	}
+	if c.Tags, err = h.TagPolicy.Resolve(tags); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.Cluster = r.FormValue("cluster")
+
+	if err := h.submitClaim(r.Context(), c, start); err != nil {
+		http.Error(w, err.Error(), statusFor(err))
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/claims?ns=%s&type=%s", ns, t), http.StatusFound)
+}
+
+// submitClaim creates a validated claim and records the submission metrics, latency measured from start
+func (h *Handler) submitClaim(ctx context.Context, c *Claim, start time.Time) error {
+	labels := h.Metrics.ClaimLabels(c.Kind(), c.Region, c.Namespace)
+	defer func() {
+		h.Metrics.ClaimLatency.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	}()
+
+	if err := h.CreateClaim(ctx, c); err != nil {
+		h.Metrics.ClaimsFailed.WithLabelValues(labels...).Inc()
+		return err
+	}
+	h.Metrics.ClaimsSubmitted.WithLabelValues(labels...).Inc()
+	return nil
+}
+
+// newClaim validates user input shared by the HTML form and the JSON API
+func (h *Handler) newClaim(t, name, ns, region, ttl string) (*Claim, error) {
 	// Validating the name to match Kubernetes DNS subdomain rules
-	name := strings.ToLower(r.FormValue("name"))
+	name = strings.ToLower(name)
 	if !validDNSName.MatchString(name) || len(name) > 63 {
 		log.Printf("❌ Invalid claim name: %s", name)
-		http.Error(w, "Invalid claim name: must match [a-z0-9]([-a-z0-9]*[a-z0-9])? and < 64 characters", http.StatusBadRequest)
-		return
+		return nil, fmt.Errorf("Invalid claim name: must match [a-z0-9]([-a-z0-9]*[a-z0-9])? and < 64 characters")
+	}
+
+	if err := checkRegion(region); err != nil {
+		return nil, err
 	}
 
 	gv := h.VerifyGVR(Resource(t))
 	if gv == nil {
-		http.Error(w, fmt.Sprintf("❌ Resource *%v* not found in supported GVRs", t), http.StatusBadRequest)
-		return
+		return nil, fmt.Errorf("❌ Resource *%v* not found in supported GVRs", t)
 	}
 
-	c := &Claim{
+	lifetime, err := h.ttlBounds(t).Resolve(ttl)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return nil, err
+	}
+
+	return &Claim{
 		Name: name,
+		kind: h.KindOf(Resource(t)),
 		GVR: schema.GroupVersionResource{
 			Group:    gv.Group,
 			Version:  gv.Version,
 			Resource: t,
 		},
-		Region:    r.FormValue("region"),
+		Region:    region,
 		Namespace: ns,
+		TTL:       lifetime,
+	}, nil
+}
+
+func checkRegion(region string) error {
+	if !slices.Contains(Regions, region) {
+		log.Printf("❌ Invalid region: %s", region)
+		return fmt.Errorf("Invalid region: must be one of %v", Regions)
 	}
+	return nil
+}
 
-	defer func() {
-		h.Metrics.ClaimLatency.
-			WithLabelValues(c.Region, c.Namespace).
-			Observe(time.Since(start).Seconds())
-	}()
+// TemplateDir is where the Dockerfile copies web/templates
+var TemplateDir = "/web/templates"
 
-	if err := h.CreateClaim(r.Context(), c); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		h.Metrics.ClaimsFailed.WithLabelValues(c.Region, c.Namespace).Inc()
-		return
-	}
+var templates atomic.Pointer[template.Template]
 
-	h.Metrics.ClaimsSubmitted.WithLabelValues(c.Region, c.Namespace).Inc()
+var templateFuncs = template.FuncMap{"lower": strings.ToLower}
 
-	http.Redirect(w, r, fmt.Sprintf("/claims?ns=%s&type=%s", ns, t), http.StatusFound)
+func parseTemplates() (*template.Template, error) {
+	return template.New("").Funcs(templateFuncs).ParseGlob(filepath.Join(TemplateDir, "*.html"))
+}
+
+// LoadTemplates parses every page once; the readiness probe fails until it succeeds
+func LoadTemplates() error {
+	t, err := parseTemplates()
+	if err != nil {
+		return fmt.Errorf("error parsing templates in %s: %w", TemplateDir, err)
+	}
+	for _, page := range []string{"index.html", "list.html", "view.html", "edit.html", "webhooks.html"} {
+		if t.Lookup(page) == nil {
+			return fmt.Errorf("template %s not found in %s", page, TemplateDir)
+		}
+	}
+	templates.Store(t)
+	return nil
+}
+
+// TemplatesLoaded is a health check for LoadTemplates
+func TemplatesLoaded(context.Context) error {
+	if templates.Load() == nil {
+		return fmt.Errorf("templates not loaded from %s", TemplateDir)
+	}
+	return nil
 }
 
 func loadTemplates() *template.Template {
-	return template.Must(template.ParseFiles(
-		"/web/templates/view.html",
-		"/web/templates/edit.html",
-		"/web/templates/index.html",
-		"/web/templates/list.html",
-	))
+	if t := templates.Load(); t != nil {
+		return t
+	}
+	return template.Must(parseTemplates())
 }
 
-var validPath = regexp.MustCompile("^/(submit|edit|view)/([a-zA-Z0-9]+)$")
+// Regions supported by the compositions (spec.location)
+var Regions = []string{"US", "EU"}
+
+var validPath = regexp.MustCompile("^/(submit|edit|view|extend|clone)/([a-zA-Z0-9-]+)$")
 var validDNSName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
 
-func ViewHandler(w http.ResponseWriter, r *http.Request, claim string) {
-	// TODO: Pull from ETCD
-	c := &Claim{Name: claim, Region: "US"}
-	renderTemplate(w, "view", c)
+// ClaimPage is rendered by view.html
+type ClaimPage struct {
+	*ClaimView
+	Type     string            // resource, i.e. storage
+	Estimate *pricing.Estimate // nil when the pricing table has no rate for the claim
+	Accrued  float64
+	Limits   TTLBounds // renewals and maximum lifetime of the Extend form
+}
+
+// ViewHandler renders the detail page of the claim in ?ns= of kind ?type=
+func (h *Handler) ViewHandler(w http.ResponseWriter, r *http.Request, name string) {
+	ns := r.URL.Query().Get("ns")
+	rs := string(h.ResourceOf(r.URL.Query().Get("type")))
+	gv := h.VerifyGVR(Resource(rs))
+	if ns == "" || gv == nil {
+		http.Error(w, "query parameters ns and type are required", http.StatusBadRequest)
+		return
+	}
+	cv, err := h.GetClaim(r.Context(), ns, gv.WithResource(rs), name)
+	if err != nil {
+		http.Error(w, err.Error(), statusFor(err))
+		return
+	}
+	h.touchClaim(r.Context(), ns, gv.WithResource(rs), name, cv)
+
+	page := ClaimPage{ClaimView: cv, Type: rs, Estimate: h.claimEstimate(cv), Limits: h.ttlBounds(rs)}
+	if page.Estimate != nil {
+		page.Accrued = pricing.Accrued(page.Estimate.Hourly, cv.CreatedAt, time.Now())
+	}
+	renderTemplate(w, "view", page)
+}
+
+// ExtendHandler handles the Extend form of the detail page, then shows the claim again
+func (h *Handler) ExtendHandler(w http.ResponseWriter, r *http.Request, name string) {
+	ns := r.FormValue("ns")
+	rs := string(h.ResourceOf(r.FormValue("type")))
+	gv := h.VerifyGVR(Resource(rs))
+	if ns == "" || gv == nil {
+		http.Error(w, "fields ns and type are required", http.StatusBadRequest)
+		return
+	}
+	by, err := time.ParseDuration(r.FormValue("by"))
+	if err != nil || by <= 0 {
+		http.Error(w, "Invalid extension: must be a positive Go duration, i.e. 1h", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.extendClaim(r, ns, gv.WithResource(rs), name, by); err != nil {
+		http.Error(w, err.Error(), statusFor(err))
+		return
+	}
+	target := "/view/" + name + "?ns=" + url.QueryEscape(ns) + "&type=" + rs
+	if cluster := ClusterFrom(r.Context()); cluster != "" {
+		target += "&cluster=" + url.QueryEscape(cluster)
+	}
+	http.Redirect(w, r, target, http.StatusFound)
 }
 
 func EditHandler(w http.ResponseWriter, r *http.Request, name string) {
@@ -282,8 +621,8 @@ func MakeHandler(fn func(http.ResponseWriter, *http.Request, string)) http.Handl
 	}
 }
 
-func renderTemplate(w http.ResponseWriter, page string, c *Claim) {
-	err := loadTemplates().ExecuteTemplate(w, fmt.Sprintf("/web/templates/%s.html", page), c)
+func renderTemplate(w http.ResponseWriter, page string, data any) {
+	err := loadTemplates().ExecuteTemplate(w, page+".html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
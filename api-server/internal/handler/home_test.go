@@ -1,23 +1,35 @@
 package handler
 
 import (
+	"api-server/internal/audit"
 	"api-server/internal/metrics"
+	"api-server/internal/notify"
+	"api-server/internal/pricing"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type FakeClaimer struct {
 	ShouldFail bool
 	GVRs       map[Resource]schema.GroupVersion
+	Claims     []ClaimView
+	Connection map[string][]byte // connection Secret of every claim, nil when not published
+	Touched    []string          // names of the claims activity was recorded on
 }
 
 func (f *FakeClaimer) CreateClaim(ctx context.Context, c *Claim) error {
@@ -30,6 +42,83 @@ func (f *FakeClaimer) CreateClaim(ctx context.Context, c *Claim) error {
 func (f *FakeClaimer) GetClaims(w http.ResponseWriter, r *http.Request) {
 }
 
+func (f *FakeClaimer) ListClaims(ctx context.Context, ns string, gvr schema.GroupVersionResource) ([]ClaimView, error) {
+	if f.ShouldFail {
+		return nil, fmt.Errorf("simulated failure")
+	}
+	return f.Claims, nil
+}
+
+func (f *FakeClaimer) GetClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) (*ClaimView, error) {
+	for _, c := range f.Claims {
+		if c.Namespace == ns && c.Name == name {
+			return &c, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+}
+
+func (f *FakeClaimer) UpdateClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, u ClaimUpdate) (*ClaimView, error) {
+	return &ClaimView{Name: name, Namespace: ns, Location: u.Region}, nil
+}
+
+func (f *FakeClaimer) ExtendClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string, by time.Duration, bounds TTLBounds) (*ClaimView, error) {
+	renewals := 0
+	for _, c := range f.Claims {
+		if c.Namespace == ns && c.Name == name {
+			renewals = c.Renewals
+		}
+	}
+	ttl, err := bounds.Extend(DefaultTTL, renewals, by)
+	if err != nil {
+		return nil, err
+	}
+	return &ClaimView{Name: name, Namespace: ns, TTL: ttl.String(), Renewals: renewals + 1}, nil
+}
+
+func (f *FakeClaimer) ConnectionDetails(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) (map[string][]byte, error) {
+	if f.Connection == nil {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name+ConnectionSecretSuffix)
+	}
+	return f.Connection, nil
+}
+
+func (f *FakeClaimer) TouchClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) error {
+	if _, err := f.GetClaim(ctx, ns, gvr, name); err != nil {
+		return err
+	}
+	f.Touched = append(f.Touched, name)
+	return nil
+}
+
+func (f *FakeClaimer) DeleteClaim(ctx context.Context, ns string, gvr schema.GroupVersionResource, name string) error {
+	if f.ShouldFail {
+		return fmt.Errorf("simulated failure")
+	}
+	return nil
+}
+
+func (f *FakeClaimer) CachedClaims(ns string) ([]ClaimView, error) {
+	if f.ShouldFail {
+		return nil, fmt.Errorf("simulated failure")
+	}
+	var cvs []ClaimView
+	for _, cv := range f.Claims {
+		if ns == "" || cv.Namespace == ns {
+			cvs = append(cvs, cv)
+		}
+	}
+	return cvs, nil
+}
+
+func (f *FakeClaimer) KindOf(r Resource) string {
+	return titleKind(r)
+}
+
+func (f *FakeClaimer) ResourceOf(t string) Resource {
+	return Resource(strings.ToLower(t))
+}
+
 func (f *FakeClaimer) VerifyGVR(r Resource) *schema.GroupVersion {
 	gv, ok := f.GVRs[r]
 	if !ok {
@@ -104,5 +193,443 @@ func TestSubmitHandler_InvalidNamespace(t *testing.T) {
 
 	h.SubmitHandler(rr, req)
 	assert.Equal(t, http.StatusInternalServerError, rr.Code)
-	assert.Equal(t, 1, int(testutil.ToFloat64(h.Metrics.ClaimsFailed.WithLabelValues("US", "missing"))))
+	assert.Equal(t, 1, int(testutil.ToFloat64(h.Metrics.ClaimsFailed.WithLabelValues("Storage", "US", metrics.OtherNamespace))))
+}
+
+func TestCreateClaimAPI(t *testing.T) {
+	body := `{"type":"Storage","name":"mystorage","namespace":"dev","region":"US","tags":{"team":"data"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/claims", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	h := &Handler{
+		Claimer: &FakeClaimer{
+			GVRs: map[Resource]schema.GroupVersion{
+				"storage": {
+					Group:   "platform.example.org",
+					Version: "v1alpha1",
+				},
+			},
+		},
+		Metrics: metrics.InitPrometheus("dev"),
+	}
+
+	h.CreateClaimAPI(rr, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.JSONEq(t, `{"name":"mystorage","kind":"Storage","namespace":"dev","region":"US","status":"Unknown","ttl":"10m0s",
+		"tags":{"team":"data","project":"unassigned","cost-center":"unassigned"}}`, rr.Body.String())
+	assert.Equal(t, 1, int(testutil.ToFloat64(h.Metrics.ClaimsSubmitted.WithLabelValues("Storage", "US", "dev"))))
+}
+
+func TestCreateClaimAPI_InvalidRegion(t *testing.T) {
+	body := `{"type":"Storage","name":"mystorage","namespace":"dev","region":"APAC"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/claims", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	h := &Handler{
+		Claimer: new(FakeClaimer),     // not used in this test
+		Metrics: new(metrics.Metrics), // not used in this test
+	}
+
+	h.CreateClaimAPI(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":400`)
+}
+
+func TestClaimAPI_Routes(t *testing.T) {
+	h := &Handler{
+		Claimer: &FakeClaimer{
+			GVRs: map[Resource]schema.GroupVersion{
+				"storage": {
+					Group:   "platform.example.org",
+					Version: "v1alpha1",
+				},
+			},
+			Claims: []ClaimView{
+				{Name: "mystorage", Namespace: "dev", Location: "US"},
+				{Name: "renewed", Namespace: "dev", Location: "US", Renewals: 3},
+			},
+		},
+		Metrics: metrics.InitPrometheus(),
+	}
+	r := chi.NewRouter()
+	r.Get("/api/v1/claims/{type}/{namespace}/{name}", h.GetClaimAPI)
+	r.Patch("/api/v1/claims/{type}/{namespace}/{name}", h.UpdateClaimAPI)
+	r.Post("/api/v1/claims/{type}/{namespace}/{name}/extend", h.ExtendClaimAPI)
+	r.Delete("/api/v1/claims/{type}/{namespace}/{name}", h.DeleteClaimAPI)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{"get", http.MethodGet, "/api/v1/claims/Storage/dev/mystorage", "", http.StatusOK},
+		{"get missing", http.MethodGet, "/api/v1/claims/Storage/dev/missing", "", http.StatusNotFound},
+		{"get unknown kind", http.MethodGet, "/api/v1/claims/Microservice/dev/mystorage", "", http.StatusNotFound},
+		{"edit", http.MethodPatch, "/api/v1/claims/storage/dev/mystorage", `{"region":"EU"}`, http.StatusOK},
+		{"edit invalid region", http.MethodPatch, "/api/v1/claims/storage/dev/mystorage", `{"region":"APAC"}`, http.StatusBadRequest},
+		{"extend", http.MethodPost, "/api/v1/claims/storage/dev/mystorage/extend", `{"duration":"1h"}`, http.StatusOK},
+		{"extend negative", http.MethodPost, "/api/v1/claims/storage/dev/mystorage/extend", `{"duration":"-1h"}`, http.StatusBadRequest},
+		{"extend past max lifetime", http.MethodPost, "/api/v1/claims/storage/dev/mystorage/extend", `{"duration":"100h"}`, http.StatusConflict},
+		{"extend past max renewals", http.MethodPost, "/api/v1/claims/storage/dev/renewed/extend", `{"duration":"1h"}`, http.StatusConflict},
+		{"delete", http.MethodDelete, "/api/v1/claims/storage/dev/mystorage", "", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			assert.Equal(t, tt.code, rr.Code, rr.Body.String())
+		})
+	}
+}
+
+func TestInventoryItem_NotReadySince(t *testing.T) {
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	transition := created.Add(time.Hour)
+
+	ready := inventoryItem("Storage", ClaimView{Namespace: "dev", Location: "US", Status: "Ready", CreatedAt: created})
+	assert.True(t, ready.NotReadySince.IsZero())
+
+	pending := inventoryItem("Storage", ClaimView{Status: "Unknown", CreatedAt: created})
+	assert.Equal(t, created, pending.NotReadySince)
+
+	degraded := inventoryItem("Storage", ClaimView{Status: "NotReady", CreatedAt: created, Conditions: []ClaimCondition{
+		{Type: "Synced", Status: "True"},
+		{Type: "Ready", Status: "False", LastTransitionTime: transition},
+	}})
+	assert.Equal(t, transition, degraded.NotReadySince)
+	assert.Equal(t, "Storage", degraded.Kind)
+}
+
+func TestCostsAPI(t *testing.T) {
+	created := time.Now().Add(-2 * time.Hour)
+	h := &Handler{
+		Claimer: &FakeClaimer{Claims: []ClaimView{
+			{Name: "a", Kind: "Storage", Namespace: "dev", Location: "US", CreatedAt: created},
+			{Name: "b", Kind: "Storage", Namespace: "dev", Location: "EU", CreatedAt: created},
+			{Name: "c", Kind: "Compute", Namespace: "ml", Location: "US", CreatedAt: created}, // not priced
+		}},
+		Pricing: &pricing.Table{Currency: "USD", Rates: []pricing.Rate{
+			{Kind: "Storage", Region: "US", Hourly: 0.5},
+			{Kind: "Storage", Region: "EU", Hourly: 1},
+		}},
+	}
+
+	rr := httptest.NewRecorder()
+	h.CostsAPI(rr, httptest.NewRequest(http.MethodGet, "/api/v1/costs", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var report CostReport
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.Unpriced)
+	assert.Len(t, report.Claims, 2)
+	assert.Equal(t, []NamespaceCost{{Namespace: "dev", Claims: 2, Hourly: 1.5, Accrued: 3}}, report.Namespaces)
+}
+
+func TestEstimateAPI(t *testing.T) {
+	h := &Handler{
+		Claimer: &FakeClaimer{GVRs: map[Resource]schema.GroupVersion{
+			"storage": {Group: "platform.example.org", Version: "v1alpha1"},
+		}},
+		Pricing: &pricing.Table{Currency: "USD", Rates: []pricing.Rate{{Kind: "Storage", Region: "US", Hourly: 0.5}}},
+	}
+
+	rr := httptest.NewRecorder()
+	h.EstimateAPI(rr, httptest.NewRequest(http.MethodGet, "/api/v1/estimate?type=Storage&region=US&ttl=4h", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"currency":"USD","hourly":0.5,"ttl":"4h0m0s","total":2}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	h.EstimateAPI(rr, httptest.NewRequest(http.MethodGet, "/api/v1/estimate?type=storage&region=EU", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestClaimTransitions(t *testing.T) {
+	pending := ClaimView{Status: "NotReady", Conditions: []ClaimCondition{{Type: "Synced", Status: "True"}, {Type: "Ready", Status: "False"}}}
+	ready := ClaimView{Status: "Ready", Conditions: []ClaimCondition{{Type: "Synced", Status: "True"}, {Type: "Ready", Status: "True"}}}
+	broken := ClaimView{Status: "Unknown", Conditions: []ClaimCondition{{Type: "Synced", Status: "False", Message: "cannot compose"}}}
+
+	assert.Equal(t, []notify.EventType{notify.Ready}, claimTransitions(pending, ready))
+	assert.Equal(t, []notify.EventType{notify.Failed}, claimTransitions(ready, pending))
+	assert.Equal(t, []notify.EventType{notify.Failed}, claimTransitions(pending, broken))
+	assert.Empty(t, claimTransitions(broken, broken))
+	assert.Equal(t, "cannot compose", conditionMessage(broken))
+}
+
+func TestExpiringClaims_WarnsOncePerExpiry(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cv := ClaimView{Kind: "Storage", Namespace: "dev", Name: "mystorage", ExpiresAt: now.Add(2 * time.Minute)}
+	var events []notify.Event
+	publish := func(ev notify.Event) { events = append(events, ev) }
+
+	warned := expiringClaims([]ClaimView{cv}, nil, now, 5*time.Minute, publish)
+	warned = expiringClaims([]ClaimView{cv}, warned, now.Add(time.Minute), 5*time.Minute, publish)
+	require.Len(t, events, 1)
+	assert.Equal(t, notify.Expiring, events[0].Type)
+	assert.Equal(t, "expires in 2m0s", events[0].Message)
+
+	// extending the claim re-arms the warning once the claim-controller recorded the new expiry
+	cv.ExpiresAt = time.Time{}
+	warned = expiringClaims([]ClaimView{cv}, warned, now.Add(time.Minute), 5*time.Minute, publish)
+	assert.Len(t, events, 1)
+	cv.ExpiresAt = now.Add(3 * time.Minute)
+	expiringClaims([]ClaimView{cv}, warned, now.Add(time.Minute), 5*time.Minute, publish)
+	assert.Len(t, events, 2)
+}
+
+func TestWebhookAPI(t *testing.T) {
+	h := &Handler{Notifier: notify.New(notify.NewMemoryStore())}
+	r := chi.NewRouter()
+	r.Get("/api/v1/webhooks/{namespace}", h.GetWebhookAPI)
+	r.Put("/api/v1/webhooks/{namespace}", h.PutWebhookAPI)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/webhooks/dev", strings.NewReader(body))
+		req.Header.Set(audit.ActorHeader, "dev")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusBadRequest, put(`{"url":"not a url"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, put(`{"url":"http://kubernetes.default.svc/api"}`).Code)
+	assert.Equal(t, http.StatusOK, put(`{"url":"https://hooks.example.com/a","secret":"s3cret"}`).Code)
+	// an empty secret keeps the current one
+	rr := put(`{"url":"https://hooks.example.com/b","events":["claim.ready"]}`)
+	assert.JSONEq(t, `{"namespace":"dev","url":"https://hooks.example.com/b","hasSecret":true,"events":["claim.ready"]}`, rr.Body.String())
+
+	sub, err := h.Notifier.Store().Get(context.Background(), "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", sub.Secret)
+
+	get := func(ns, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/"+ns, nil)
+		if user != "" {
+			req.Header.Set(audit.ActorHeader, user)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+	assert.Equal(t, http.StatusNotFound, get("ml", "ml").Code)
+	// only the owner sees or replaces the webhook of a namespace
+	assert.Equal(t, http.StatusForbidden, get("dev", "mallory").Code)
+	assert.Equal(t, http.StatusForbidden, get("dev", "").Code)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/webhooks/dev", strings.NewReader(`{"url":"https://evil.example.com"}`))
+	req.Header.Set(audit.ActorHeader, "mallory")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestCreateClaimAPI_TTLBounds(t *testing.T) {
+	h := &Handler{
+		Claimer: &FakeClaimer{GVRs: map[Resource]schema.GroupVersion{
+			"storage": {Group: "platform.example.org", Version: "v1alpha1"},
+		}},
+		Metrics:   metrics.InitPrometheus(),
+		TTLBounds: map[Resource]TTLBounds{"storage": {Min: 10 * time.Minute, Max: 8 * time.Hour, Default: time.Hour}},
+	}
+
+	tests := []struct {
+		ttl  string
+		code int
+		want string
+	}{
+		{"", http.StatusCreated, `"ttl":"1h0m0s"`},
+		{"4h", http.StatusCreated, `"ttl":"4h0m0s"`},
+		{"9h", http.StatusBadRequest, "must be between 10m0s and 8h0m0s"},
+		{"soon", http.StatusBadRequest, "must be a Go duration"},
+	}
+	for _, tt := range tests {
+		body := fmt.Sprintf(`{"type":"Storage","name":"mystorage","namespace":"dev","region":"US","ttl":%q}`, tt.ttl)
+		rr := httptest.NewRecorder()
+		h.CreateClaimAPI(rr, httptest.NewRequest(http.MethodPost, "/api/v1/claims", strings.NewReader(body)))
+		assert.Equal(t, tt.code, rr.Code, tt.ttl)
+		assert.Contains(t, rr.Body.String(), tt.want)
+	}
+}
+
+func TestParseTTLBounds(t *testing.T) {
+	bounds, err := ParseTTLBounds(`{"storage":{"max":"8h"},"compute":{"min":"15m","max":"2h","default":"30m","maxRenewals":0,"maxLifetime":"2h"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, TTLBounds{
+		Min: DefaultTTLBounds.Min, Max: 8 * time.Hour, Default: DefaultTTL,
+		MaxRenewals: DefaultTTLBounds.MaxRenewals, MaxLifetime: DefaultTTLBounds.MaxLifetime,
+	}, bounds["storage"])
+	assert.Equal(t, TTLBounds{Min: 15 * time.Minute, Max: 2 * time.Hour, Default: 30 * time.Minute, MaxLifetime: 2 * time.Hour}, bounds["compute"])
+
+	_, err = ParseTTLBounds(`{"storage":{"min":"1h","max":"30m"}}`)
+	assert.Error(t, err)
+	_, err = ParseTTLBounds(`{"storage":{"max":"8h","maxLifetime":"4h"}}`)
+	assert.Error(t, err)
+}
+
+func TestTTLBounds_Extend(t *testing.T) {
+	b := TTLBounds{MaxRenewals: 2, MaxLifetime: 4 * time.Hour}
+
+	ttl, err := b.Extend(time.Hour, 0, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, ttl)
+
+	_, err = b.Extend(time.Hour, 2, time.Hour)
+	assert.ErrorIs(t, err, ErrExtendLimit)
+	_, err = b.Extend(3*time.Hour, 1, 2*time.Hour)
+	assert.ErrorIs(t, err, ErrExtendLimit)
+	_, err = b.Extend(time.Hour, 0, 0)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrExtendLimit)
+}
+
+func TestExtendClaimAPI_Audit(t *testing.T) {
+	var trail bytes.Buffer
+	h := &Handler{
+		Claimer: &FakeClaimer{
+			GVRs:   map[Resource]schema.GroupVersion{"storage": {Group: "platform.example.org", Version: "v1alpha1"}},
+			Claims: []ClaimView{{Name: "renewed", Namespace: "dev", Renewals: 3}},
+		},
+		Audit: audit.New(&trail),
+	}
+	r := chi.NewRouter()
+	r.Post("/api/v1/claims/{type}/{namespace}/{name}/extend", h.ExtendClaimAPI)
+
+	for _, name := range []string{"mystorage", "renewed"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/claims/storage/dev/"+name+"/extend", strings.NewReader(`{"duration":"1h"}`))
+		req.Header.Set(audit.ActorHeader, "alice")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	dec := json.NewDecoder(&trail)
+	var allowed, denied audit.Entry
+	require.NoError(t, dec.Decode(&allowed))
+	require.NoError(t, dec.Decode(&denied))
+	assert.Equal(t, audit.Allowed, allowed.Outcome)
+	assert.Equal(t, "alice", allowed.Actor)
+	assert.Equal(t, "1h10m0s", allowed.Details["ttl"])
+	assert.Equal(t, "1", allowed.Details["renewals"])
+	assert.Equal(t, audit.Denied, denied.Outcome)
+	assert.Contains(t, denied.Details["reason"], "renewed 3 of 3")
+}
+
+func TestTagPolicy_Resolve(t *testing.T) {
+	p := TagPolicy{Required: []string{"team"}, Allowed: map[string][]string{"team": {"data", "web"}}}
+
+	tags, err := p.Resolve(map[string]string{"team": "data", "project": "demo"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "data", "project": "demo", "cost-center": UnassignedTag}, tags)
+	assert.Equal(t, tags, claimTags(tagLabels(tags)))
+
+	for _, invalid := range []map[string]string{
+		nil,                              // missing required tag
+		{"team": "finance"},              // not an allowed value
+		{"team": "data", "owner": "bob"}, // unknown tag
+		{"team": "data", "project": "not a label value"},
+	} {
+		_, err := p.Resolve(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseTagPolicy(t *testing.T) {
+	p, err := ParseTagPolicy(`{"required":["team","cost-center"],"allowed":{"team":["data","web"]}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team", "cost-center"}, p.Required)
+
+	_, err = ParseTagPolicy(`{"required":["owner"]}`)
+	assert.Error(t, err)
+}
+
+func TestConnectionAPI(t *testing.T) {
+	var trail bytes.Buffer
+	h := &Handler{
+		Claimer: &FakeClaimer{
+			GVRs:       map[Resource]schema.GroupVersion{"storage": {Group: "platform.example.org", Version: "v1alpha1"}},
+			Connection: map[string][]byte{"bucket": []byte("dev-mystorage"), "tableArn": []byte("arn:aws:dynamodb:us-west-2:1:table/t"), "password": []byte("it's")},
+		},
+		Audit: audit.New(&trail),
+	}
+	r := chi.NewRouter()
+	r.Get("/api/v1/claims/{type}/{namespace}/{name}/connection", h.ConnectionAPI)
+	get := func(path, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if user != "" {
+			req.Header.Set(audit.ActorHeader, user)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("/api/v1/claims/storage/dev/mystorage/connection", "dev")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var conn Connection
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &conn))
+	assert.Equal(t, []ConnectionDetail{
+		{Key: "bucket", Value: "dev-mystorage"},
+		{Key: "password", Value: Masked, Secret: true},
+		{Key: "tableArn", Value: "arn:aws:dynamodb:us-west-2:1:table/t"},
+	}, conn.Details)
+	assert.Empty(t, conn.Env)
+	assert.Zero(t, trail.Len(), "reading masked details is not audited")
+
+	rr = get("/api/v1/claims/storage/dev/mystorage/connection?reveal=true", "dev")
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &conn))
+	assert.Equal(t, "it's", conn.Details[1].Value)
+	assert.Contains(t, conn.Env, `export STORAGE_PASSWORD='it'\''s'`)
+	assert.Contains(t, conn.Env, "export STORAGE_TABLE_ARN=")
+
+	rr = get("/api/v1/claims/storage/dev/mystorage/connection?reveal=true", "mallory")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	dec := json.NewDecoder(&trail)
+	var allowed, denied audit.Entry
+	require.NoError(t, dec.Decode(&allowed))
+	require.NoError(t, dec.Decode(&denied))
+	assert.Equal(t, "claim.connection.reveal", allowed.Action)
+	assert.Equal(t, audit.Allowed, allowed.Outcome)
+	assert.Equal(t, audit.Denied, denied.Outcome)
+	assert.Equal(t, "mallory", denied.Actor)
+
+	// without an authenticating proxy nobody is the owner
+	assert.Equal(t, http.StatusForbidden, get("/api/v1/claims/storage/dev/mystorage/connection", "").Code)
+
+	h.Claimer.(*FakeClaimer).Connection = nil
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/claims/storage/dev/mystorage/connection", "dev").Code)
+}
+
+func TestCloneClaimAPI(t *testing.T) {
+	h := &Handler{
+		Claimer: &FakeClaimer{
+			GVRs: map[Resource]schema.GroupVersion{"storage": {Group: "platform.example.org", Version: "v1alpha1"}},
+			Claims: []ClaimView{
+				{Name: "mystorage", Namespace: "dev", Location: "US", TTL: "2h0m0s", Tags: map[string]string{"team": "data", "project": "demo"}},
+				{Name: "extended", Namespace: "dev", Location: "US", TTL: "30h0m0s"},
+			},
+		},
+		Metrics: metrics.InitPrometheus("dev"),
+	}
+	r := chi.NewRouter()
+	r.Post("/api/v1/claims/{type}/{namespace}/{name}/clone", h.CloneClaimAPI)
+	clone := func(name, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/claims/storage/dev/"+name+"/clone", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := clone("mystorage", `{"name":"copy","region":"EU","tags":{"project":"rerun"}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `{"name":"copy","kind":"Storage","namespace":"dev","region":"EU","status":"Unknown","ttl":"2h0m0s",
+		"tags":{"team":"data","project":"rerun","cost-center":"unassigned"}}`, rr.Body.String())
+	assert.Equal(t, 1, int(testutil.ToFloat64(h.Metrics.ClaimsSubmitted.WithLabelValues("Storage", "EU", "dev"))))
+
+	// an extended TTL beyond the submission bounds falls back to the default
+	rr = clone("extended", `{"name":"copy"}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"ttl":"10m0s"`)
+
+	assert.Equal(t, http.StatusBadRequest, clone("mystorage", `{"name":"mystorage"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, clone("mystorage", `{"name":"copy","region":"APAC"}`).Code)
+	assert.Equal(t, http.StatusNotFound, clone("missing", `{"name":"copy"}`).Code)
 }
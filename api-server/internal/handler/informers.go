@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"api-server/internal/metrics"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// informerResync re-delivers every cached claim to event handlers periodically
+const informerResync = 10 * time.Minute
+
+// ClaimGVRs lists the registered claim resources in a stable order
+func (k *KubeClient) ClaimGVRs() []schema.GroupVersionResource {
+	gvrs := make([]schema.GroupVersionResource, 0, len(k.GVRs))
+	for rs, gv := range k.GVRs {
+		gvrs = append(gvrs, gv.WithResource(string(rs)))
+	}
+	slices.SortFunc(gvrs, func(a, b schema.GroupVersionResource) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return gvrs
+}
+
+// StartInformers watches every registered claim resource until ctx is cancelled
+func (k *KubeClient) StartInformers(ctx context.Context) {
+	for _, gvr := range k.ClaimGVRs() {
+		k.Informers.ForResource(gvr) // registers the informer before Start
+	}
+	k.Informers.Start(ctx.Done())
+}
+
+// InformersSynced is a health check that passes once every claim cache has been filled
+func (k *KubeClient) InformersSynced(context.Context) error {
+	var pending []string
+	for _, gvr := range k.ClaimGVRs() {
+		if !k.Informers.ForResource(gvr).Informer().HasSynced() {
+			pending = append(pending, gvr.GroupResource().String())
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("caches not synced: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// CachedClaims lists the claims in ns (every namespace when empty) from the informer caches,
+// it never calls the kube-apiserver
+func (k *KubeClient) CachedClaims(ns string) ([]ClaimView, error) {
+	if err := k.InformersSynced(context.Background()); err != nil {
+		return nil, err
+	}
+
+	var cvs []ClaimView
+	for _, gvr := range k.ClaimGVRs() {
+		lister := k.Informers.ForResource(gvr).Lister()
+		var objs []runtime.Object
+		var err error
+		if ns == "" {
+			objs, err = lister.List(labels.Everything())
+		} else {
+			objs, err = lister.ByNamespace(ns).List(labels.Everything())
+		}
+		if err != nil {
+			return nil, err
+		}
+		kind := k.KindOf(Resource(gvr.Resource))
+		for _, obj := range objs {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			cv := newClaimView(u)
+			cv.Kind = kind
+			cvs = append(cvs, cv)
+		}
+	}
+	return cvs, nil
+}
+
+// Inventory lists every claim from the informer caches for the inventory metrics
+func (k *KubeClient) Inventory() ([]metrics.InventoryItem, error) {
+	cvs, err := k.CachedClaims("")
+	if err != nil {
+		return nil, err
+	}
+	items := make([]metrics.InventoryItem, 0, len(cvs))
+	for _, cv := range cvs {
+		items = append(items, inventoryItem(cv.Kind, cv))
+	}
+	return items, nil
+}
+
+func inventoryItem(kind string, cv ClaimView) metrics.InventoryItem {
+	item := metrics.InventoryItem{
+		Kind:      kind,
+		Namespace: cv.Namespace,
+		Region:    cv.Location,
+		Preset:    cv.Preset,
+		Status:    cv.Status,
+		CreatedAt: cv.CreatedAt,
+	}
+	if cv.Status != "Ready" {
+		item.NotReadySince = cv.CreatedAt
+		for _, c := range cv.Conditions {
+			if c.Type == "Ready" && !c.LastTransitionTime.IsZero() {
+				item.NotReadySince = c.LastTransitionTime
+			}
+		}
+	}
+	return item
+}
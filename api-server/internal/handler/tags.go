@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// TagLabelPrefix namespaces the claim labels holding cost-allocation tags, i.e. platform.example.org/team;
+	// the compositions copy them into the forProvider.tags of every AWS resource
+	TagLabelPrefix = "platform.example.org/"
+	// UnassignedTag is the value of optional tags the user left empty, so every AWS resource carries every tag
+	UnassignedTag = "unassigned"
+)
+
+// TagKeys are the cost-allocation tags users set on claims
+var TagKeys = []string{"team", "project", "cost-center"}
+
+// TagPolicy is set by admins: which tags must be supplied, and the values allowed per tag
+type TagPolicy struct {
+	Required []string            `json:"required,omitempty"`
+	Allowed  map[string][]string `json:"allowed,omitempty"` // tags without a list accept any label value
+}
+
+// Resolve validates the tags of a new claim and returns one value per TagKeys, UnassignedTag for those omitted
+func (p TagPolicy) Resolve(tags map[string]string) (map[string]string, error) {
+	for k := range tags {
+		if !slices.Contains(TagKeys, k) {
+			return nil, fmt.Errorf("Invalid tag %q: must be one of %v", k, TagKeys)
+		}
+	}
+
+	resolved := make(map[string]string, len(TagKeys))
+	for _, k := range TagKeys {
+		v := strings.TrimSpace(tags[k])
+		if v == "" || v == UnassignedTag {
+			if slices.Contains(p.Required, k) {
+				return nil, fmt.Errorf("Missing tag %q: required tags are %v", k, p.Required)
+			}
+			resolved[k] = UnassignedTag
+			continue
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return nil, fmt.Errorf("Invalid tag %s=%q: %s", k, v, strings.Join(errs, "; "))
+		}
+		if allowed, ok := p.Allowed[k]; ok && !slices.Contains(allowed, v) {
+			return nil, fmt.Errorf("Invalid tag %s=%q: must be one of %v", k, v, allowed)
+		}
+		resolved[k] = v
+	}
+	return resolved, nil
+}
+
+// ParseTagPolicy reads the policy from JSON, i.e. the TAG_POLICY environment variable:
+//
+//	{"required": ["team", "cost-center"], "allowed": {"team": ["data", "web"]}}
+func ParseTagPolicy(s string) (TagPolicy, error) {
+	var p TagPolicy
+	if err := json.Unmarshal([]byte(s), &p); err != nil {
+		return p, fmt.Errorf("invalid tag policy: %w", err)
+	}
+	for _, k := range slices.Concat(p.Required, slices.Collect(maps.Keys(p.Allowed))) {
+		if !slices.Contains(TagKeys, k) {
+			return p, fmt.Errorf("invalid tag policy: unknown tag %q, must be one of %v", k, TagKeys)
+		}
+	}
+	return p, nil
+}
+
+// tagLabels are the claim labels holding tags
+func tagLabels(tags map[string]string) map[string]string {
+	labels := make(map[string]string, len(tags))
+	for k, v := range tags {
+		labels[TagLabelPrefix+k] = v
+	}
+	return labels
+}
+
+// claimTags reads the tags back from claim labels
+func claimTags(labels map[string]string) map[string]string {
+	var tags map[string]string
+	for _, k := range TagKeys {
+		if v, ok := labels[TagLabelPrefix+k]; ok {
+			if tags == nil {
+				tags = map[string]string{}
+			}
+			tags[k] = v
+		}
+	}
+	return tags
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrExtendLimit is returned when extending a claim would exceed its renewals or maximum lifetime
+var ErrExtendLimit = errors.New("extension limit reached")
+
+// TTLBounds are the lifetimes users may request for a claim kind, set by admins
+type TTLBounds struct {
+	Min     time.Duration
+	Max     time.Duration
+	Default time.Duration // used when no lifetime is requested
+	// MaxRenewals is how many times a claim may be extended, MaxLifetime the longest TTL extensions may reach
+	MaxRenewals int
+	MaxLifetime time.Duration
+}
+
+// DefaultTTLBounds apply to kinds without admin-set bounds
+var DefaultTTLBounds = TTLBounds{Min: 5 * time.Minute, Max: 24 * time.Hour, Default: DefaultTTL, MaxRenewals: 3, MaxLifetime: 72 * time.Hour}
+
+// Resolve validates a requested lifetime (a Go duration, i.e. 4h), empty meaning the default
+func (b TTLBounds) Resolve(requested string) (time.Duration, error) {
+	if requested == "" {
+		return b.Default, nil
+	}
+	ttl, err := time.ParseDuration(requested)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid ttl %q: must be a Go duration, i.e. 4h", requested)
+	}
+	if ttl < b.Min || ttl > b.Max {
+		return 0, fmt.Errorf("Invalid ttl %s: must be between %s and %s", ttl, b.Min, b.Max)
+	}
+	return ttl, nil
+}
+
+// Extend returns the TTL of a claim extended by, given how many times it was renewed before
+func (b TTLBounds) Extend(ttl time.Duration, renewals int, by time.Duration) (time.Duration, error) {
+	if by <= 0 {
+		return 0, fmt.Errorf("extension must be positive, got %s", by)
+	}
+	if renewals >= b.MaxRenewals {
+		return 0, fmt.Errorf("%w: already renewed %d of %d times", ErrExtendLimit, renewals, b.MaxRenewals)
+	}
+	if ttl+by > b.MaxLifetime {
+		return 0, fmt.Errorf("%w: a ttl of %s exceeds the maximum lifetime of %s", ErrExtendLimit, ttl+by, b.MaxLifetime)
+	}
+	return ttl + by, nil
+}
+
+// ttlBounds of a claim resource, i.e. storage
+func (h *Handler) ttlBounds(rs string) TTLBounds {
+	if b, ok := h.TTLBounds[Resource(rs)]; ok {
+		return b
+	}
+	return DefaultTTLBounds
+}
+
+// ParseTTLBounds reads the bounds per resource from JSON, i.e. the TTL_BOUNDS environment variable:
+//
+//	{"storage": {"min": "5m", "max": "8h", "default": "1h", "maxRenewals": 3, "maxLifetime": "24h"}}
+//
+// Missing fields are taken from DefaultTTLBounds.
+func ParseTTLBounds(s string) (map[Resource]TTLBounds, error) {
+	var raw map[Resource]struct {
+		Min         string `json:"min"`
+		Max         string `json:"max"`
+		Default     string `json:"default"`
+		MaxRenewals *int   `json:"maxRenewals"`
+		MaxLifetime string `json:"maxLifetime"`
+	}
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return nil, fmt.Errorf("invalid ttl bounds: %w", err)
+	}
+
+	bounds := make(map[Resource]TTLBounds, len(raw))
+	for rs, r := range raw {
+		b := DefaultTTLBounds
+		for _, f := range []struct {
+			v   string
+			dst *time.Duration
+		}{{r.Min, &b.Min}, {r.Max, &b.Max}, {r.Default, &b.Default}, {r.MaxLifetime, &b.MaxLifetime}} {
+			if f.v == "" {
+				continue
+			}
+			d, err := time.ParseDuration(f.v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ttl bounds for %s: %w", rs, err)
+			}
+			*f.dst = d
+		}
+		if b.Min <= 0 || b.Min > b.Max || b.Default < b.Min || b.Default > b.Max {
+			return nil, fmt.Errorf("invalid ttl bounds for %s: need 0 < min <= default <= max, got %s <= %s <= %s", rs, b.Min, b.Default, b.Max)
+		}
+		if r.MaxRenewals != nil {
+			b.MaxRenewals = *r.MaxRenewals
+		}
+		if b.MaxRenewals < 0 || b.MaxLifetime < b.Max {
+			return nil, fmt.Errorf("invalid ttl bounds for %s: need maxRenewals >= 0 and maxLifetime >= max, got %d and %s", rs, b.MaxRenewals, b.MaxLifetime)
+		}
+		bounds[rs] = b
+	}
+	return bounds, nil
+}
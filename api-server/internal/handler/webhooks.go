@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"api-server/internal/notify"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// WebhookRequest is the JSON body accepted when registering the webhook of a namespace
+type WebhookRequest struct {
+	URL    string             `json:"url"`
+	Secret string             `json:"secret,omitempty"` // keeps the current secret when empty
+	Events []notify.EventType `json:"events,omitempty"`
+}
+
+// WebhookView is a subscription with its secret redacted
+type WebhookView struct {
+	Namespace string             `json:"namespace"`
+	URL       string             `json:"url"`
+	HasSecret bool               `json:"hasSecret"`
+	Events    []notify.EventType `json:"events,omitempty"`
+}
+
+func newWebhookView(s *notify.Subscription) *WebhookView {
+	return &WebhookView{Namespace: s.Namespace, URL: s.URL, HasSecret: s.Secret != "", Events: s.Events}
+}
+
+// ownsWebhook writes a 403 unless the authenticated user owns ns: the webhook of a namespace, its signing secret
+// and its dead letters are its owner's only
+func ownsWebhook(w http.ResponseWriter, r *http.Request, ns string) bool {
+	if isOwner(r, ns) {
+		return true
+	}
+	writeError(w, http.StatusForbidden, "webhooks are only managed by the owner of namespace "+ns)
+	return false
+}
+
+// GetWebhookAPI returns the webhook of a namespace
+func (h *Handler) GetWebhookAPI(w http.ResponseWriter, r *http.Request) {
+	ns := chi.URLParam(r, "namespace")
+	if !ownsWebhook(w, r, ns) {
+		return
+	}
+	sub, err := h.Notifier.Store().Get(r.Context(), ns)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if sub == nil {
+		writeError(w, http.StatusNotFound, "no webhook registered for "+ns)
+		return
+	}
+	writeJSON(w, http.StatusOK, newWebhookView(sub))
+}
+
+// PutWebhookAPI registers or replaces the webhook of a namespace
+func (h *Handler) PutWebhookAPI(w http.ResponseWriter, r *http.Request) {
+	ns := chi.URLParam(r, "namespace")
+	if !ownsWebhook(w, r, ns) {
+		return
+	}
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	sub, status, err := h.saveWebhook(r.Context(), ns, req)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, newWebhookView(sub))
+}
+
+// DeleteWebhookAPI stops notifications for a namespace
+func (h *Handler) DeleteWebhookAPI(w http.ResponseWriter, r *http.Request) {
+	ns := chi.URLParam(r, "namespace")
+	if !ownsWebhook(w, r, ns) {
+		return
+	}
+	if err := h.Notifier.Store().Delete(r.Context(), ns); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeadLettersAPI lists the events that could not be delivered to the webhook of a namespace
+func (h *Handler) DeadLettersAPI(w http.ResponseWriter, r *http.Request) {
+	ns := chi.URLParam(r, "namespace")
+	if !ownsWebhook(w, r, ns) {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.Notifier.DeadLetters(ns))
+}
+
+func (h *Handler) saveWebhook(ctx context.Context, ns string, req WebhookRequest) (*notify.Subscription, int, error) {
+	current, err := h.Notifier.Store().Get(ctx, ns)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	sub := &notify.Subscription{Namespace: ns, URL: req.URL, Secret: req.Secret, Events: req.Events}
+	if sub.Secret == "" && current != nil {
+		sub.Secret = current.Secret
+	}
+	if err := sub.Validate(); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if err := h.Notifier.Store().Put(ctx, *sub); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return sub, http.StatusOK, nil
+}
+
+// WebhooksPage is rendered by webhooks.html
+type WebhooksPage struct {
+	Namespace   string
+	Webhook     *WebhookView // nil when none is registered
+	EventTypes  []notify.EventType
+	DeadLetters []notify.DeadLetter
+}
+
+// Subscribed reports whether the page's webhook selected t, for the event checkboxes
+func (p WebhooksPage) Subscribed(t notify.EventType) bool {
+	if p.Webhook == nil {
+		return true
+	}
+	s := notify.Subscription{Events: p.Webhook.Events}
+	return s.Wants(t)
+}
+
+// WebhooksHandler renders the webhook settings of ?ns=
+func (h *Handler) WebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	ns := r.URL.Query().Get("ns")
+	if ns == "" {
+		http.Error(w, "query parameter ns is required", http.StatusBadRequest)
+		return
+	}
+	if !isOwner(r, ns) {
+		http.Error(w, "webhooks are only managed by the owner of namespace "+ns, http.StatusForbidden)
+		return
+	}
+	page := WebhooksPage{Namespace: ns, EventTypes: notify.EventTypes, DeadLetters: h.Notifier.DeadLetters(ns)}
+	sub, err := h.Notifier.Store().Get(r.Context(), ns)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if sub != nil {
+		page.Webhook = newWebhookView(sub)
+	}
+	renderTemplate(w, "webhooks", page)
+}
+
+// SaveWebhookHandler handles the webhook settings form: action=save or action=delete
+func (h *Handler) SaveWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ns := r.FormValue("namespace")
+	if ns == "" {
+		http.Error(w, "namespace is required", http.StatusBadRequest)
+		return
+	}
+	if !isOwner(r, ns) {
+		http.Error(w, "webhooks are only managed by the owner of namespace "+ns, http.StatusForbidden)
+		return
+	}
+
+	if r.FormValue("action") == "delete" {
+		if err := h.Notifier.Store().Delete(r.Context(), ns); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		req := WebhookRequest{URL: r.FormValue("url"), Secret: r.FormValue("secret")}
+		for _, e := range r.Form["events"] {
+			req.Events = append(req.Events, notify.EventType(e))
+		}
+		if _, status, err := h.saveWebhook(r.Context(), ns, req); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+	}
+	http.Redirect(w, r, "/webhooks?ns="+url.QueryEscape(ns), http.StatusFound)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var xrdGVR = schema.GroupVersionResource{Group: "apiextensions.crossplane.io", Version: "v1", Resource: "compositeresourcedefinitions"}
+
+// DiscoverClaimKinds serves the claim kind of every XRD offering one that the api-server may list and watch, so
+// that the API, its OpenAPI document and the informers cover each claim kind the cluster has. Kinds it is not
+// granted are left out rather than keeping their informers, and so /readyz, from ever syncing. It must run before
+// StartInformers; the registered kinds are kept when the XRDs cannot be listed.
+func (k *KubeClient) DiscoverClaimKinds(ctx context.Context) error {
+	list, err := k.DynamicClient.Resource(xrdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list XRDs: %w", err)
+	}
+	gvrs, kinds := map[Resource]schema.GroupVersion{}, map[Resource]string{}
+	for _, xrd := range list.Items {
+		gvr, kind, ok := claimGVR(&xrd)
+		if !ok {
+			continue
+		}
+		if err := k.canWatch(ctx, gvr); err != nil {
+			log.Printf("❌ Not serving claim %s %s from XRD %s: %v", kind, gvr, xrd.GetName(), err)
+			continue
+		}
+		gvrs[Resource(gvr.Resource)] = gvr.GroupVersion()
+		kinds[Resource(gvr.Resource)] = kind
+		log.Printf("✅ Serving claim %s %s from XRD %s", kind, gvr, xrd.GetName())
+	}
+	k.GVRs, k.Kinds = gvrs, kinds
+	return nil
+}
+
+// canWatch checks the api-server may list and watch the claims of gvr in every namespace, as its informers do
+func (k *KubeClient) canWatch(ctx context.Context, gvr schema.GroupVersionResource) error {
+	for _, verb := range []string{"list", "watch"} {
+		review, err := k.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group: gvr.Group, Version: gvr.Version, Resource: gvr.Resource, Verb: verb,
+			}},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to review access: %w", err)
+		}
+		if !review.Status.Allowed {
+			return fmt.Errorf("%s is forbidden, see the api-server ClusterRole", verb)
+		}
+	}
+	return nil
+}
+
+// claimGVR is the claim resource and kind an XRD offers at its referenceable version, false when it offers none
+func claimGVR(xrd *unstructured.Unstructured) (schema.GroupVersionResource, string, bool) {
+	group, _, _ := unstructured.NestedString(xrd.Object, "spec", "group")
+	plural, _, _ := unstructured.NestedString(xrd.Object, "spec", "claimNames", "plural")
+	kind, _, _ := unstructured.NestedString(xrd.Object, "spec", "claimNames", "kind")
+	versions, _, _ := unstructured.NestedSlice(xrd.Object, "spec", "versions")
+	version := ""
+	for _, v := range versions {
+		v, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := v["name"].(string)
+		if referenceable, _ := v["referenceable"].(bool); referenceable {
+			version = name
+			break
+		}
+		if served, _ := v["served"].(bool); served && version == "" {
+			version = name
+		}
+	}
+	if group == "" || plural == "" || kind == "" || version == "" {
+		return schema.GroupVersionResource{}, "", false
+	}
+	return schema.GroupVersionResource{Group: group, Version: version, Resource: plural}, kind, true
+}
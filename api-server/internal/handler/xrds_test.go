@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"api-server/internal/openapi"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestXRD(name, kind, plural string, versions ...any) *unstructured.Unstructured {
+	spec := map[string]any{"group": "platform.example.org", "versions": versions}
+	if plural != "" {
+		spec["claimNames"] = map[string]any{"kind": kind, "plural": plural}
+	}
+	xrd := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	xrd.SetGroupVersionKind(xrdGVR.GroupVersion().WithKind("CompositeResourceDefinition"))
+	xrd.SetName(name)
+	return xrd
+}
+
+// newAccessClientset allows the api-server every access review except on the forbidden resources
+func newAccessClientset(forbidden ...string) *kubefake.Clientset {
+	cs := kubefake.NewClientset()
+	cs.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		allowed := true
+		for _, rs := range forbidden {
+			allowed = allowed && review.Spec.ResourceAttributes.Resource != rs
+		}
+		review.Status.Allowed = allowed
+		return true, review, nil
+	})
+	return cs
+}
+
+func TestKubeClient_DiscoverClaimKinds(t *testing.T) {
+	served := map[string]any{"name": "v1alpha1", "served": true, "referenceable": true}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		xrdGVR: "CompositeResourceDefinitionList",
+	},
+		newTestXRD("awsstorage.platform.example.org", "Storage", "storage", served),
+		newTestXRD("awscompute.platform.example.org", "Compute", "compute",
+			map[string]any{"name": "v1alpha1", "served": true}, map[string]any{"name": "v1beta1", "served": true, "referenceable": true}),
+		newTestXRD("modeldeployments.platform.example.org", "ModelDeploymentClaim", "modeldeploymentclaims", served),
+		newTestXRD("xdatabases.platform.example.org", "Database", "databases", served), // not granted by the ClusterRole
+		newTestXRD("xnetworks.platform.example.org", "", "", served),                   // no claims offered
+	)
+	k := &KubeClient{
+		DynamicClient: dyn,
+		Clientset:     newAccessClientset("databases"),
+		GVRs:          map[Resource]schema.GroupVersion{"buckets": storageGVR.GroupVersion()}, // no XRD offers it any more
+		Kinds:         map[Resource]string{"buckets": "Bucket"},
+	}
+
+	require.NoError(t, k.DiscoverClaimKinds(context.Background()))
+	assert.Equal(t, []schema.GroupVersionResource{
+		{Group: "platform.example.org", Version: "v1alpha1", Resource: "modeldeploymentclaims"},
+		{Group: "platform.example.org", Version: "v1alpha1", Resource: "storage"},
+		{Group: "platform.example.org", Version: "v1beta1", Resource: "compute"},
+	}, k.ClaimGVRs(), "forbidden and vanished kinds are not served, so their informers cannot hold up /readyz")
+	assert.Equal(t, "ModelDeploymentClaim", k.KindOf("modeldeploymentclaims"))
+	for _, typ := range []string{"modeldeploymentclaims", "ModelDeploymentClaim", "modeldeploymentclaim"} {
+		assert.Equal(t, Resource("modeldeploymentclaims"), k.ResourceOf(typ), typ)
+	}
+
+	// claims are created with the kind of their XRD
+	h := &Handler{Claimer: k}
+	c, err := h.newClaim("modeldeploymentclaims", "model", "alice", "US", "")
+	require.NoError(t, err)
+	u, err := c.object()
+	require.NoError(t, err)
+	assert.Equal(t, "ModelDeploymentClaim", u.GetKind())
+
+	// the OpenAPI document describes every discovered kind
+	var kinds []openapi.Kind
+	for _, gvr := range k.ClaimGVRs() {
+		kinds = append(kinds, openapi.Kind{Resource: gvr.Resource, Group: gvr.Group, Version: gvr.Version, Kind: k.KindOf(Resource(gvr.Resource))})
+	}
+	doc := openapi.Build("test", kinds, Regions)
+	assert.Equal(t, []string{"Compute", "ModelDeploymentClaim", "Storage"}, doc.Components.Schemas["ClaimRequest"].Properties["type"].Enum)
+	assert.Contains(t, doc.Components.Schemas, "ModelDeploymentClaim")
+}
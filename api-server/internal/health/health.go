@@ -0,0 +1,80 @@
+// Package health serves /livez, /readyz and /healthz in the style of the kube-apiserver:
+// plain "ok" by default, one [+]/[-] line per check with ?verbose, and ?exclude=name to skip a check
+package health
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Check is a named probe; a nil error means healthy
+type Check struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
+// Ping always succeeds, it only proves the HTTP server is serving
+var Ping = Check{Name: "ping", Fn: func(context.Context) error { return nil }}
+
+type result struct {
+	name string
+	err  error
+}
+
+// Handler runs the checks concurrently, each bounded by timeout
+func Handler(timeout time.Duration, checks ...Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		excluded := r.URL.Query()["exclude"]
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		results := make([]result, len(checks))
+		var wg sync.WaitGroup
+		for i, c := range checks {
+			results[i].name = c.Name
+			if slices.Contains(excluded, c.Name) {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i].err = c.Fn(ctx)
+			}()
+		}
+		wg.Wait()
+
+		var b strings.Builder
+		failed := false
+		for _, res := range results {
+			switch {
+			case slices.Contains(excluded, res.name):
+				fmt.Fprintf(&b, "[+]%s excluded: ok\n", res.name)
+			case res.err != nil:
+				failed = true
+				fmt.Fprintf(&b, "[-]%s failed: %v\n", res.name, res.err)
+			default:
+				fmt.Fprintf(&b, "[+]%s ok\n", res.name)
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if failed {
+			log.Printf("❌ %s check failed\n%s", r.URL.Path, b.String())
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "%s%s check failed\n", b.String(), strings.TrimPrefix(r.URL.Path, "/"))
+			return
+		}
+		if _, verbose := r.URL.Query()["verbose"]; verbose {
+			fmt.Fprintf(w, "%s%s check passed\n", b.String(), strings.TrimPrefix(r.URL.Path, "/"))
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}
+}
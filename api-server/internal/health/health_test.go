@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var storage = schema.GroupVersionResource{Group: "platform.example.org", Version: "v1alpha1", Resource: "storage"}
+
+func failing(name string) Check {
+	return Check{Name: name, Fn: func(context.Context) error { return errors.New("boom") }}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		checks []Check
+		code   int
+		body   []string
+	}{
+		{"ok", "", []Check{Ping}, http.StatusOK, []string{"ok"}},
+		{"verbose", "?verbose", []Check{Ping}, http.StatusOK, []string{"[+]ping ok", "readyz check passed"}},
+		{"failing", "", []Check{Ping, failing("xrds")}, http.StatusInternalServerError, []string{"[+]ping ok", "[-]xrds failed: boom"}},
+		{"excluded", "?exclude=xrds", []Check{Ping, failing("xrds")}, http.StatusOK, []string{"ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			Handler(time.Second, tt.checks...)(rr, httptest.NewRequest(http.MethodGet, "/readyz"+tt.query, nil))
+			assert.Equal(t, tt.code, rr.Code)
+			for _, b := range tt.body {
+				assert.Contains(t, rr.Body.String(), b)
+			}
+		})
+	}
+}
+
+func object(gvk schema.GroupVersionKind, name string, spec map[string]any, conditions ...string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	u.SetGroupVersionKind(gvk)
+	u.SetName(name)
+	cs := make([]any, 0, len(conditions))
+	for _, c := range conditions {
+		cs = append(cs, map[string]any{"type": c, "status": "True"})
+	}
+	_ = unstructured.SetNestedSlice(u.Object, cs, "status", "conditions")
+	return u
+}
+
+func fakeDynamic(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		xrdGVR: "CompositeResourceDefinitionList",
+		crdGVR: "CustomResourceDefinitionList",
+	}, objects...)
+}
+
+func TestXRDs(t *testing.T) {
+	xrdGVK := xrdGVR.GroupVersion().WithKind("CompositeResourceDefinition")
+	spec := map[string]any{"group": "platform.example.org", "claimNames": map[string]any{"plural": "storage"}}
+
+	ok := fakeDynamic(object(xrdGVK, "awsstorage.platform.example.org", spec, "Established", "Offered"))
+	assert.NoError(t, XRDs(ok, []schema.GroupVersionResource{storage}).Fn(context.Background()))
+
+	notOffered := fakeDynamic(object(xrdGVK, "awsstorage.platform.example.org", spec, "Established"))
+	assert.ErrorContains(t, XRDs(notOffered, []schema.GroupVersionResource{storage}).Fn(context.Background()), "is not Offered")
+
+	missing := fakeDynamic()
+	assert.ErrorContains(t, XRDs(missing, []schema.GroupVersionResource{storage}).Fn(context.Background()), "no XRD offers claim storage.platform.example.org")
+}
+
+func TestCRDs(t *testing.T) {
+	crdGVK := crdGVR.GroupVersion().WithKind("CustomResourceDefinition")
+
+	ok := fakeDynamic(object(crdGVK, "storage.platform.example.org", nil, "Established"))
+	assert.NoError(t, CRDs(ok, []schema.GroupVersionResource{storage}).Fn(context.Background()))
+
+	notEstablished := fakeDynamic(object(crdGVK, "storage.platform.example.org", nil))
+	assert.ErrorContains(t, CRDs(notEstablished, []schema.GroupVersionResource{storage}).Fn(context.Background()), "is not Established")
+
+	assert.ErrorContains(t, CRDs(fakeDynamic(), []schema.GroupVersionResource{storage}).Fn(context.Background()), "not found")
+}
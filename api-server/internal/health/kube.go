@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	xrdGVR = schema.GroupVersionResource{Group: "apiextensions.crossplane.io", Version: "v1", Resource: "compositeresourcedefinitions"}
+	crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+// KubeAPIServer checks the kube-apiserver's own /readyz
+func KubeAPIServer(cs kubernetes.Interface) Check {
+	return Check{Name: "kube-apiserver", Fn: func(ctx context.Context) error {
+		if _, err := cs.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx); err != nil {
+			return fmt.Errorf("kube-apiserver not ready: %w", err)
+		}
+		return nil
+	}}
+}
+
+// XRDs checks every claim resource is offered by a Crossplane CompositeResourceDefinition
+// that is Established and Offered
+func XRDs(dc dynamic.Interface, claims []schema.GroupVersionResource) Check {
+	return Check{Name: "xrds", Fn: func(ctx context.Context) error {
+		list, err := dc.Resource(xrdGVR).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to list XRDs: %w", err)
+		}
+
+		var problems []string
+		for _, gvr := range claims {
+			xrd := findXRD(list.Items, gvr)
+			if xrd == nil {
+				problems = append(problems, fmt.Sprintf("no XRD offers claim %s", gvr.GroupResource()))
+				continue
+			}
+			for _, cond := range []string{"Established", "Offered"} {
+				if !conditionTrue(xrd, cond) {
+					problems = append(problems, fmt.Sprintf("XRD %s is not %s", xrd.GetName(), cond))
+				}
+			}
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%s", strings.Join(problems, "; "))
+		}
+		return nil
+	}}
+}
+
+// CRDs checks the CustomResourceDefinition of every claim resource exists and is Established
+func CRDs(dc dynamic.Interface, claims []schema.GroupVersionResource) Check {
+	return Check{Name: "crds", Fn: func(ctx context.Context) error {
+		var problems []string
+		for _, gvr := range claims {
+			name := gvr.GroupResource().String() // i.e. storage.platform.example.org
+			crd, err := dc.Resource(crdGVR).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("CRD %s: %v", name, err))
+				continue
+			}
+			if !conditionTrue(crd, "Established") {
+				problems = append(problems, fmt.Sprintf("CRD %s is not Established", name))
+			}
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%s", strings.Join(problems, "; "))
+		}
+		return nil
+	}}
+}
+
+func findXRD(xrds []unstructured.Unstructured, claim schema.GroupVersionResource) *unstructured.Unstructured {
+	for i := range xrds {
+		group, _, _ := unstructured.NestedString(xrds[i].Object, "spec", "group")
+		plural, _, _ := unstructured.NestedString(xrds[i].Object, "spec", "claimNames", "plural")
+		if group == claim.Group && plural == claim.Resource {
+			return &xrds[i]
+		}
+	}
+	return nil
+}
+
+func conditionTrue(u *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, cond := range conditions {
+		if condMap, ok := cond.(map[string]any); ok && condMap["type"] == conditionType {
+			return condMap["status"] == "True"
+		}
+	}
+	return false
+}
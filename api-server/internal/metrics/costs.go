@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"api-server/internal/pricing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// costCollector prices the claims in the cluster on scrape
+type costCollector struct {
+	source    Inventory
+	table     *pricing.Table
+	namespace func(string) string
+	now       func() time.Time
+
+	hourly  *prometheus.Desc
+	accrued *prometheus.Desc
+}
+
+// RegisterCosts adds a collector that reports the hourly rate and accrued cost of live claims by kind,
+// namespace and region, priced with table. Claims without a rate in the table are left out.
+func (m *Metrics) RegisterCosts(source Inventory, table *pricing.Table) {
+	m.Registry.MustRegister(newCostCollector(source, table, m.Namespace, time.Now))
+}
+
+func newCostCollector(source Inventory, table *pricing.Table, namespace func(string) string, now func() time.Time) *costCollector {
+	labels := []string{"kind", "namespace", "region"}
+	constLabels := prometheus.Labels{"currency": table.Currency}
+	return &costCollector{
+		source:    source,
+		table:     table,
+		namespace: namespace,
+		now:       now,
+		hourly: prometheus.NewDesc(
+			"claims_cost_hourly",
+			"Hourly cost of the claims in the cluster",
+			labels, constLabels,
+		),
+		accrued: prometheus.NewDesc(
+			"claims_cost_accrued",
+			"Cost accrued by the claims in the cluster since they were created",
+			labels, constLabels,
+		),
+	}
+}
+
+func (c *costCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hourly
+	ch <- c.accrued
+}
+
+func (c *costCollector) Collect(ch chan<- prometheus.Metric) {
+	items, err := c.source.Inventory()
+	if err != nil {
+		return // see inventoryCollector.Collect
+	}
+
+	type group struct{ kind, namespace, region string }
+	hourly := map[group]float64{}
+	accrued := map[group]float64{}
+
+	now := c.now()
+	for _, it := range items {
+		rate, err := c.table.Hourly(it.Kind, it.Preset, it.Region)
+		if err != nil {
+			continue
+		}
+		g := group{it.Kind, c.namespace(it.Namespace), it.Region}
+		hourly[g] += rate
+		accrued[g] += pricing.Accrued(rate, it.CreatedAt, now)
+	}
+
+	for g, v := range hourly {
+		ch <- prometheus.MustNewConstMetric(c.hourly, prometheus.GaugeValue, pricing.Round(v), g.kind, g.namespace, g.region)
+		ch <- prometheus.MustNewConstMetric(c.accrued, prometheus.GaugeValue, pricing.Round(accrued[g]), g.kind, g.namespace, g.region)
+	}
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"api-server/internal/pricing"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostCollector(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	m := InitPrometheus("dev")
+	source := fakeInventory{items: []InventoryItem{
+		{Kind: "Storage", Namespace: "dev", Region: "US", CreatedAt: now.Add(-time.Hour)},
+		{Kind: "Storage", Namespace: "dev", Region: "US", CreatedAt: now.Add(-2 * time.Hour)},
+		{Kind: "Compute", Namespace: "dev", Region: "US", CreatedAt: now}, // no rate
+	}}
+	table := &pricing.Table{Currency: "USD", Rates: []pricing.Rate{{Kind: "Storage", Region: "US", Hourly: 0.5}}}
+	c := newCostCollector(source, table, m.Namespace, func() time.Time { return now })
+
+	expected := `
+# HELP claims_cost_accrued Cost accrued by the claims in the cluster since they were created
+# TYPE claims_cost_accrued gauge
+claims_cost_accrued{currency="USD",kind="Storage",namespace="dev",region="US"} 1.5
+# HELP claims_cost_hourly Hourly cost of the claims in the cluster
+# TYPE claims_cost_hourly gauge
+claims_cost_hourly{currency="USD",kind="Storage",namespace="dev",region="US"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// InventoryItem is a claim as currently seen in the cluster
+type InventoryItem struct {
+	Kind      string
+	Namespace string
+	Region    string
+	Preset    string
+	Status    string // Ready, NotReady or Unknown
+	CreatedAt time.Time
+	// NotReadySince is when the claim last stopped being Ready (or its creation time if it never was), zero when Ready
+	NotReadySince time.Time
+}
+
+// Inventory lists every claim in the cluster. Implementations should read from a cache: it is called on every scrape.
+type Inventory interface {
+	Inventory() ([]InventoryItem, error)
+}
+
+// inventoryCollector reports the claims that exist in the cluster, whoever created them, so the numbers
+// survive restarts of the api-server unlike the counters in Metrics
+type inventoryCollector struct {
+	source        Inventory
+	notReadyAfter time.Duration
+	namespace     func(string) string
+	now           func() time.Time
+
+	claims    *prometheus.Desc
+	oldestAge *prometheus.Desc
+	stalled   *prometheus.Desc
+}
+
+// RegisterInventory adds a collector that reports live claim counts by kind, namespace, region and status,
+// the age of the oldest claim and the claims that have not been Ready for longer than notReadyAfter.
+// Namespaces are capped by the same allow-list as the claim metrics.
+func (m *Metrics) RegisterInventory(source Inventory, notReadyAfter time.Duration) {
+	m.Registry.MustRegister(newInventoryCollector(source, notReadyAfter, m.Namespace, time.Now))
+}
+
+func newInventoryCollector(source Inventory, notReadyAfter time.Duration, namespace func(string) string, now func() time.Time) *inventoryCollector {
+	return &inventoryCollector{
+		source:        source,
+		notReadyAfter: notReadyAfter,
+		namespace:     namespace,
+		now:           now,
+		claims: prometheus.NewDesc(
+			"claims",
+			"Number of claims in the cluster",
+			[]string{"kind", "namespace", "region", "status"}, nil,
+		),
+		oldestAge: prometheus.NewDesc(
+			"claims_oldest_age_seconds",
+			"Age of the oldest claim in the cluster",
+			[]string{"kind", "namespace"}, nil,
+		),
+		stalled: prometheus.NewDesc(
+			"claims_not_ready_stalled",
+			fmt.Sprintf("Number of claims that have not been Ready for longer than %s", notReadyAfter),
+			[]string{"kind", "namespace"}, nil,
+		),
+	}
+}
+
+func (c *inventoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.claims
+	ch <- c.oldestAge
+	ch <- c.stalled
+}
+
+func (c *inventoryCollector) Collect(ch chan<- prometheus.Metric) {
+	items, err := c.source.Inventory()
+	if err != nil {
+		// the caches are not synced yet: reporting zero claims would be a lie, so report nothing
+		return
+	}
+
+	type count struct{ kind, namespace, region, status string }
+	type group struct{ kind, namespace string }
+	counts := map[count]int{}
+	oldest := map[group]time.Time{}
+	stalled := map[group]int{}
+
+	now := c.now()
+	for _, it := range items {
+		ns := c.namespace(it.Namespace)
+		counts[count{it.Kind, ns, it.Region, it.Status}]++
+
+		g := group{it.Kind, ns}
+		if created, ok := oldest[g]; !ok || it.CreatedAt.Before(created) {
+			oldest[g] = it.CreatedAt
+		}
+		if _, ok := stalled[g]; !ok {
+			stalled[g] = 0 // a zero series lets alerts tell "none stalled" from "no data"
+		}
+		if !it.NotReadySince.IsZero() && now.Sub(it.NotReadySince) > c.notReadyAfter {
+			stalled[g]++
+		}
+	}
+
+	for k, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.claims, prometheus.GaugeValue, float64(n), k.kind, k.namespace, k.region, k.status)
+	}
+	for g, created := range oldest {
+		ch <- prometheus.MustNewConstMetric(c.oldestAge, prometheus.GaugeValue, now.Sub(created).Seconds(), g.kind, g.namespace)
+	}
+	for g, n := range stalled {
+		ch <- prometheus.MustNewConstMetric(c.stalled, prometheus.GaugeValue, float64(n), g.kind, g.namespace)
+	}
+}
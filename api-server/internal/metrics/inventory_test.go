@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeInventory struct {
+	items []InventoryItem
+	err   error
+}
+
+func (f fakeInventory) Inventory() ([]InventoryItem, error) { return f.items, f.err }
+
+func TestInventoryCollector(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	m := InitPrometheus("dev")
+	source := fakeInventory{items: []InventoryItem{
+		{Kind: "Storage", Namespace: "dev", Region: "US", Status: "Ready", CreatedAt: now.Add(-time.Hour)},
+		{Kind: "Storage", Namespace: "dev", Region: "US", Status: "NotReady", CreatedAt: now.Add(-30 * time.Minute), NotReadySince: now.Add(-20 * time.Minute)},
+		{Kind: "Storage", Namespace: "dev", Region: "EU", Status: "Unknown", CreatedAt: now.Add(-time.Minute), NotReadySince: now.Add(-time.Minute)},
+		{Kind: "Storage", Namespace: "alice", Region: "EU", Status: "Ready", CreatedAt: now.Add(-2 * time.Hour)},
+	}}
+	c := newInventoryCollector(source, 15*time.Minute, m.Namespace, func() time.Time { return now })
+
+	expected := `
+# HELP claims Number of claims in the cluster
+# TYPE claims gauge
+claims{kind="Storage",namespace="dev",region="EU",status="Unknown"} 1
+claims{kind="Storage",namespace="dev",region="US",status="NotReady"} 1
+claims{kind="Storage",namespace="dev",region="US",status="Ready"} 1
+claims{kind="Storage",namespace="other",region="EU",status="Ready"} 1
+# HELP claims_not_ready_stalled Number of claims that have not been Ready for longer than 15m0s
+# TYPE claims_not_ready_stalled gauge
+claims_not_ready_stalled{kind="Storage",namespace="dev"} 1
+claims_not_ready_stalled{kind="Storage",namespace="other"} 0
+# HELP claims_oldest_age_seconds Age of the oldest claim in the cluster
+# TYPE claims_oldest_age_seconds gauge
+claims_oldest_age_seconds{kind="Storage",namespace="dev"} 3600
+claims_oldest_age_seconds{kind="Storage",namespace="other"} 7200
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+}
+
+func TestInventoryCollector_NotSynced(t *testing.T) {
+	m := InitPrometheus()
+	c := newInventoryCollector(fakeInventory{err: errors.New("caches not synced")}, time.Minute, m.Namespace, time.Now)
+
+	assert.Equal(t, 0, testutil.CollectAndCount(c))
+}
@@ -8,22 +8,38 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// OtherNamespace replaces namespaces outside the allow-list in claim metrics
+const OtherNamespace = "other"
+
 // Prometheus and Grafana friendly
 type Metrics struct {
 	Registry        *prometheus.Registry
 	ClaimsSubmitted *prometheus.CounterVec
 	ClaimsFailed    *prometheus.CounterVec
 	ClaimLatency    *prometheus.HistogramVec
-	Uptime          prometheus.Gauge
+	StartTime       prometheus.Gauge
+
+	HTTPRequests *prometheus.CounterVec
+	HTTPDuration *prometheus.HistogramVec
+	HTTPInFlight *prometheus.GaugeVec
+
+	// namespaces that keep their own series, every other namespace is reported as OtherNamespace
+	namespaces map[string]bool
 }
 
-func InitPrometheus() *Metrics {
+// InitPrometheus registers every metric on a new registry. Claim metrics are labelled by kind,
+// region and namespace; kind and region are validated before they are recorded, so only the
+// namespace needs an allow-list to keep cardinality bounded.
+func InitPrometheus(namespaces ...string) *Metrics {
 	reg := prometheus.NewRegistry()
 	reg.MustRegister(
 		collectors.NewGoCollector(),
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 	)
 
+	claimLabels := []string{"kind", "region", "namespace"}
+	httpLabels := []string{"route", "method", "code"}
+
 	m := &Metrics{
 		Registry: reg,
 		ClaimsSubmitted: promauto.With(reg).NewCounterVec(
@@ -31,14 +47,14 @@ func InitPrometheus() *Metrics {
 				Name: "claims_submitted_total",
 				Help: "Total number of claims submitted",
 			},
-			[]string{"region", "username"},
+			claimLabels,
 		),
 		ClaimsFailed: promauto.With(reg).NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "claims_failed_total",
 				Help: "Total number of failed claims",
 			},
-			[]string{"region", "username"},
+			claimLabels,
 		),
 		ClaimLatency: promauto.With(reg).NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -46,23 +62,57 @@ func InitPrometheus() *Metrics {
 				Help:    "Latency for submitting a claim",
 				Buckets: prometheus.DefBuckets, // reasonable defaults
 			},
-			[]string{"region", "username"},
+			claimLabels,
 		),
-		Uptime: promauto.With(reg).NewGauge(
+		// uptime is time() - control_plane_start_time_seconds, no need to keep a gauge ticking
+		StartTime: promauto.With(reg).NewGauge(
 			prometheus.GaugeOpts{
-				Name: "control_plane_uptime_seconds",
-				Help: "How long the control plane has been up",
+				Name: "control_plane_start_time_seconds",
+				Help: "Start time of the control plane since unix epoch in seconds",
+			},
+		),
+		HTTPRequests: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_requests_total",
+				Help: "Total number of HTTP requests by route pattern, method and status code",
+			},
+			httpLabels,
+		),
+		HTTPDuration: promauto.With(reg).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_duration_seconds",
+				Help:    "HTTP request latency by route pattern, method and status code",
+				Buckets: prometheus.DefBuckets,
 			},
+			httpLabels,
 		),
+		HTTPInFlight: promauto.With(reg).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "http_requests_in_flight",
+				Help: "HTTP requests currently being served by route pattern and method",
+			},
+			[]string{"route", "method"},
+		),
+		namespaces: make(map[string]bool, len(namespaces)),
 	}
 
-	go func() {
-		start := time.Now()
-		for {
-			m.Uptime.Set(time.Since(start).Seconds())
-			time.Sleep(5 * time.Second)
-		}
-	}()
+	for _, ns := range namespaces {
+		m.namespaces[ns] = true
+	}
+	m.StartTime.Set(float64(time.Now().Unix()))
 
 	return m
 }
+
+// ClaimLabels returns the label values for the claim metrics, with the namespace capped by the allow-list
+func (m *Metrics) ClaimLabels(kind, region, namespace string) []string {
+	return []string{kind, region, m.Namespace(namespace)}
+}
+
+// Namespace is the namespace label value: the namespace itself when allow-listed, OtherNamespace otherwise
+func (m *Metrics) Namespace(namespace string) string {
+	if !m.namespaces[namespace] {
+		return OtherNamespace
+	}
+	return namespace
+}
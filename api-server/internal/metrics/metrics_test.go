@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimLabels_AllowList(t *testing.T) {
+	m := InitPrometheus("dev", "ml")
+
+	assert.Equal(t, []string{"Storage", "US", "dev"}, m.ClaimLabels("Storage", "US", "dev"))
+	assert.Equal(t, []string{"Compute", "EU", OtherNamespace}, m.ClaimLabels("Compute", "EU", "alice"))
+}
+
+func TestMiddleware_LabelsByRoutePattern(t *testing.T) {
+	m := InitPrometheus()
+
+	r := chi.NewRouter()
+	r.Use(m.Middleware(r))
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/claims/{type}/{namespace}/{name}", func(w http.ResponseWriter, r *http.Request) {
+			// in flight while the handler runs
+			assert.Equal(t, 1, int(testutil.ToFloat64(m.HTTPInFlight.WithLabelValues("/api/v1/claims/{type}/{namespace}/{name}", http.MethodGet))))
+			w.WriteHeader(http.StatusNotFound)
+		})
+	})
+
+	for _, path := range []string{"/api/v1/claims/storage/dev/a", "/api/v1/claims/storage/dev/b", "/wp-login.php"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2, int(testutil.ToFloat64(m.HTTPRequests.WithLabelValues("/api/v1/claims/{type}/{namespace}/{name}", http.MethodGet, "404"))))
+	assert.Equal(t, 1, int(testutil.ToFloat64(m.HTTPRequests.WithLabelValues(UnmatchedRoute, http.MethodGet, "404"))))
+	assert.Equal(t, 0, int(testutil.ToFloat64(m.HTTPInFlight.WithLabelValues("/api/v1/claims/{type}/{namespace}/{name}", http.MethodGet))))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.HTTPDuration))
+}
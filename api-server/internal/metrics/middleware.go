@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// UnmatchedRoute labels requests that match no route, so scanners cannot create a series per path
+const UnmatchedRoute = "unmatched"
+
+// knownMethods bounds the method label, any other verb is reported as "other"
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// Middleware records the RED metrics (rate, errors, duration) of every request. Requests are
+// labelled by the chi route pattern (i.e. /api/v1/claims/{type}/{namespace}/{name}) rather than the
+// path, which is resolved up front so the in-flight gauge can carry it too.
+func (m *Metrics) Middleware(routes chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+			if route == "" {
+				route = UnmatchedRoute
+			}
+
+			method := r.Method
+			if !knownMethods[method] {
+				method = "other"
+			}
+
+			inFlight := m.HTTPInFlight.WithLabelValues(route, method)
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			code := strconv.Itoa(status)
+			m.HTTPRequests.WithLabelValues(route, method, code).Inc()
+			m.HTTPDuration.WithLabelValues(route, method, code).Observe(time.Since(start).Seconds())
+		})
+	}
+}
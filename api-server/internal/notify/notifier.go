@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	queueSize      = 256
+	workers        = 4
+	maxDeadLetters = 50 // per namespace, oldest dropped first
+)
+
+// Notifier queues events and delivers them to the subscription of the claim's namespace
+type Notifier struct {
+	store       Store
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+
+	queue chan Event
+
+	mu          sync.Mutex
+	deadLetters map[string][]DeadLetter
+}
+
+type Option func(*Notifier)
+
+// WithHTTPClient replaces the default client, which times out after 10s
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) { n.client = c }
+}
+
+// WithRetries sets how many times a delivery is attempted, waiting backoff, 2*backoff, 4*backoff, ...
+// between attempts, before it becomes a dead letter
+func WithRetries(attempts int, backoff time.Duration) Option {
+	return func(n *Notifier) {
+		n.maxAttempts = attempts
+		n.backoff = backoff
+	}
+}
+
+func New(store Store, opts ...Option) *Notifier {
+	n := &Notifier{
+		store:       store,
+		client:      publicClient(),
+		maxAttempts: 5,
+		backoff:     time.Second,
+		queue:       make(chan Event, queueSize),
+		deadLetters: map[string][]DeadLetter{},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Store holds the subscriptions the notifier delivers to
+func (n *Notifier) Store() Store {
+	return n.store
+}
+
+// Publish queues an event without blocking; events are dropped (and logged) when the queue is full
+func (n *Notifier) Publish(ev Event) {
+	select {
+	case n.queue <- ev:
+	default:
+		log.Printf("❌ Notification queue full, dropping %s for %s/%s", ev.Type, ev.Claim.Namespace, ev.Claim.Name)
+	}
+}
+
+// Run delivers queued events until ctx is cancelled
+func (n *Notifier) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case ev := <-n.queue:
+					n.dispatch(ctx, ev)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (n *Notifier) dispatch(ctx context.Context, ev Event) {
+	sub, err := n.store.Get(ctx, ev.Claim.Namespace)
+	if err != nil {
+		log.Printf("❌ Error reading webhook of %s: %v", ev.Claim.Namespace, err)
+		return
+	}
+	if sub == nil || !sub.Wants(ev.Type) {
+		return
+	}
+	if err := n.Deliver(ctx, *sub, ev); err != nil {
+		log.Printf("❌ Webhook %s for %s/%s failed: %v", ev.Type, ev.Claim.Namespace, ev.Claim.Name, err)
+	}
+}
+
+// Deliver POSTs the signed event to the subscription, retrying network errors, 429 and 5xx responses.
+// The event is recorded as a dead letter when every attempt fails.
+func (n *Notifier) Deliver(ctx context.Context, sub Subscription, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("error encoding event: %w", err)
+	}
+
+	var lastErr error
+	attempt := 0
+	for attempt < n.maxAttempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(n.backoff << (attempt - 1)):
+			}
+		}
+		attempt++
+
+		retry, err := n.post(ctx, sub, ev, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	n.deadLetter(DeadLetter{Event: ev, URL: sub.URL, Attempts: attempt, Error: lastErr.Error(), Time: time.Now().UTC()})
+	return fmt.Errorf("giving up after %d attempts: %w", attempt, lastErr)
+}
+
+// post sends one attempt, reporting whether a failure may be retried
+func (n *Notifier) post(ctx context.Context, sub Subscription, ev Event, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(ev.Type))
+	req.Header.Set(DeliveryHeader, ev.ID)
+	if sub.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(sub.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+}
+
+func (n *Notifier) deadLetter(dl DeadLetter) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ns := dl.Event.Claim.Namespace
+	n.deadLetters[ns] = append(n.deadLetters[ns], dl)
+	if over := len(n.deadLetters[ns]) - maxDeadLetters; over > 0 {
+		n.deadLetters[ns] = n.deadLetters[ns][over:]
+	}
+}
+
+// DeadLetters returns the undelivered events of a namespace, oldest first
+func (n *Notifier) DeadLetters(namespace string) []DeadLetter {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]DeadLetter{}, n.deadLetters[namespace]...)
+}
@@ -0,0 +1,117 @@
+// Package notify delivers claim lifecycle events to the webhooks users register for their namespace.
+// Payloads are JSON signed with HMAC-SHA256, failed deliveries are retried with exponential backoff
+// and recorded as dead letters once every attempt failed.
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+type EventType string
+
+const (
+	Created  EventType = "claim.created"
+	Ready    EventType = "claim.ready"
+	Failed   EventType = "claim.failed"
+	Expiring EventType = "claim.expiring"
+	Deleted  EventType = "claim.deleted"
+)
+
+// EventTypes lists every event a subscription can select
+var EventTypes = []EventType{Created, Ready, Failed, Expiring, Deleted}
+
+const (
+	// SignatureHeader carries "sha256=" + the hex HMAC-SHA256 of the body keyed with the subscription secret
+	SignatureHeader = "X-Platform-Signature"
+	EventHeader     = "X-Platform-Event"
+	DeliveryHeader  = "X-Platform-Delivery"
+)
+
+// Claim identifies the claim an event is about
+type Claim struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Region    string    `json:"region"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+	Cluster   string    `json:"cluster,omitempty"` // set when the api-server manages several clusters
+}
+
+// Event is the JSON payload POSTed to webhooks
+type Event struct {
+	ID      string    `json:"id"`
+	Type    EventType `json:"type"`
+	Time    time.Time `json:"time"`
+	Claim   Claim     `json:"claim"`
+	Message string    `json:"message,omitempty"`
+}
+
+// NewEvent stamps an event with a unique delivery ID and the current time
+func NewEvent(t EventType, c Claim, message string) Event {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return Event{ID: hex.EncodeToString(b), Type: t, Time: time.Now().UTC(), Claim: c, Message: message}
+}
+
+// Subscription is the webhook of a namespace. In this platform a user's claims live in the
+// namespace named after them, so this is also the per-user webhook.
+type Subscription struct {
+	Namespace string      `json:"namespace"`
+	URL       string      `json:"url"`
+	Secret    string      `json:"secret,omitempty"`
+	Events    []EventType `json:"events,omitempty"` // every event when empty
+}
+
+// Wants reports whether the subscription selected the event type
+func (s *Subscription) Wants(t EventType) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, t)
+}
+
+// Validate checks the URL is absolute http(s) on a public host and every event type is known
+func (s *Subscription) Validate() error {
+	if s.Namespace == "" {
+		return fmt.Errorf("namespace is required")
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL, got %q", s.URL)
+	}
+	if err := checkHost(u.Hostname()); err != nil {
+		return err
+	}
+	for _, t := range s.Events {
+		if !slices.Contains(EventTypes, t) {
+			return fmt.Errorf("unknown event %q, must be one of %v", t, EventTypes)
+		}
+	}
+	return nil
+}
+
+// DeadLetter records an event that could not be delivered
+type DeadLetter struct {
+	Event    Event     `json:"event"`
+	URL      string    `json:"url"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	Time     time.Time `json:"time"`
+}
+
+// Sign returns the SignatureHeader value of body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a SignatureHeader value, for receivers written in Go
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
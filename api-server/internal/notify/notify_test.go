@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var claim = Claim{Kind: "Storage", Namespace: "dev", Name: "mystorage", Region: "US", Status: "Ready"}
+
+func TestDeliver_SignsPayload(t *testing.T) {
+	received := make(chan Event, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.True(t, Verify("s3cret", body, r.Header.Get(SignatureHeader)))
+		assert.Equal(t, string(Ready), r.Header.Get(EventHeader))
+		var ev Event
+		require.NoError(t, json.Unmarshal(body, &ev))
+		received <- ev
+	}))
+	defer srv.Close()
+
+	n := New(NewMemoryStore(), WithHTTPClient(srv.Client()))
+	ev := NewEvent(Ready, claim, "")
+	require.NoError(t, n.Deliver(context.Background(), Subscription{Namespace: "dev", URL: srv.URL, Secret: "s3cret"}, ev))
+
+	got := <-received
+	assert.Equal(t, ev.ID, got.ID)
+	assert.Equal(t, claim, got.Claim)
+}
+
+func TestDeliver_RetriesThenDeadLetters(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	n := New(NewMemoryStore(), WithHTTPClient(srv.Client()), WithRetries(3, time.Millisecond))
+	err := n.Deliver(context.Background(), Subscription{Namespace: "dev", URL: srv.URL}, NewEvent(Failed, claim, "boom"))
+
+	assert.ErrorContains(t, err, "giving up after 3 attempts")
+	assert.Equal(t, int32(3), calls.Load())
+	dls := n.DeadLetters("dev")
+	require.Len(t, dls, 1)
+	assert.Equal(t, 3, dls[0].Attempts)
+	assert.Equal(t, Failed, dls[0].Event.Type)
+}
+
+func TestDeliver_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer srv.Close()
+
+	n := New(NewMemoryStore(), WithHTTPClient(srv.Client()), WithRetries(3, time.Millisecond))
+	assert.Error(t, n.Deliver(context.Background(), Subscription{Namespace: "dev", URL: srv.URL}, NewEvent(Created, claim, "")))
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Len(t, n.DeadLetters("dev"), 1)
+}
+
+func TestRun_DeliversSelectedEvents(t *testing.T) {
+	received := make(chan EventType, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- EventType(r.Header.Get(EventHeader))
+	}))
+	defer srv.Close()
+
+	store := NewMemoryStore()
+	require.NoError(t, store.Put(context.Background(), Subscription{Namespace: "dev", URL: srv.URL, Events: []EventType{Ready}}))
+	n := New(store, WithHTTPClient(srv.Client()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	n.Publish(NewEvent(Created, claim, "")) // not selected
+	n.Publish(NewEvent(Ready, claim, ""))
+
+	select {
+	case got := <-received:
+		assert.Equal(t, Ready, got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not delivered")
+	}
+}
+
+func TestSubscription_Validate(t *testing.T) {
+	assert.NoError(t, (&Subscription{Namespace: "dev", URL: "https://hooks.example.com/x"}).Validate())
+	assert.Error(t, (&Subscription{Namespace: "dev", URL: "hooks.example.com"}).Validate())
+	assert.Error(t, (&Subscription{Namespace: "dev", URL: "https://hooks.example.com", Events: []EventType{"claim.renamed"}}).Validate())
+	for _, u := range []string{
+		"http://localhost:8080/x",
+		"http://127.0.0.1/x",
+		"http://[::1]/x",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.1/x",
+		"http://[::ffff:192.168.1.1]/x",
+		"http://100.64.0.1/x",
+		"http://kubernetes.default.svc/api",
+		"http://api-server.platform.svc.cluster.local:8080/x",
+		"http://api-server/x",
+	} {
+		assert.Error(t, (&Subscription{Namespace: "dev", URL: u}).Validate(), u)
+	}
+}
+
+func TestDeliver_RefusesInternalAddresses(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	// as if a public name now resolved to the loopback address of the test server
+	n := New(NewMemoryStore(), WithRetries(1, time.Millisecond))
+	err := n.Deliver(context.Background(), Subscription{Namespace: "dev", URL: srv.URL}, NewEvent(Ready, claim, ""))
+	assert.ErrorContains(t, err, "is internal")
+	assert.Zero(t, calls.Load())
+}
+
+func TestSecretStore(t *testing.T) {
+	ctx := context.Background()
+	store := &SecretStore{Clientset: fake.NewClientset(), Namespace: "platform", Name: "api-server-webhooks"}
+
+	sub, err := store.Get(ctx, "dev")
+	require.NoError(t, err)
+	assert.Nil(t, sub)
+
+	require.NoError(t, store.Put(ctx, Subscription{Namespace: "dev", URL: "https://a.example.com", Secret: "x"}))
+	require.NoError(t, store.Put(ctx, Subscription{Namespace: "ml", URL: "https://b.example.com"}))
+
+	sub, err = store.Get(ctx, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "x", sub.Secret)
+
+	require.NoError(t, store.Delete(ctx, "dev"))
+	subs, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, "ml", subs[0].Namespace)
+}
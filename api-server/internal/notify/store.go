@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// Store persists one Subscription per namespace
+type Store interface {
+	Get(ctx context.Context, namespace string) (*Subscription, error) // nil, nil when there is none
+	List(ctx context.Context) ([]Subscription, error)
+	Put(ctx context.Context, s Subscription) error
+	Delete(ctx context.Context, namespace string) error
+}
+
+// MemoryStore keeps subscriptions in memory, for tests and local development
+type MemoryStore struct {
+	mu   sync.RWMutex
+	subs map[string]Subscription
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{subs: map[string]Subscription{}}
+}
+
+func (m *MemoryStore) Get(_ context.Context, namespace string) (*Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.subs[namespace]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (m *MemoryStore) List(context.Context) ([]Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	subs := make([]Subscription, 0, len(m.subs))
+	for _, s := range m.subs {
+		subs = append(subs, s)
+	}
+	slices.SortFunc(subs, func(a, b Subscription) int { return strings.Compare(a.Namespace, b.Namespace) })
+	return subs, nil
+}
+
+func (m *MemoryStore) Put(_ context.Context, s Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subs[s.Namespace] = s
+	return nil
+}
+
+func (m *MemoryStore) Delete(_ context.Context, namespace string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.subs, namespace)
+	return nil
+}
+
+// SecretStore keeps every subscription in a single Secret, one JSON document per namespace key,
+// so webhook secrets survive restarts and are not readable by users who can only read ConfigMaps
+type SecretStore struct {
+	Clientset kubernetes.Interface
+	Namespace string // where the api-server runs
+	Name      string
+}
+
+// secret returns the Secret, or an empty one that does not exist yet
+func (s *SecretStore) secret(ctx context.Context) (*corev1.Secret, bool, error) {
+	secret, err := s.Clientset.CoreV1().Secrets(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: s.Name, Namespace: s.Namespace}}, false, nil
+	}
+	return secret, err == nil, err
+}
+
+func (s *SecretStore) Get(ctx context.Context, namespace string) (*Subscription, error) {
+	secret, _, err := s.secret(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := secret.Data[namespace]
+	if !ok {
+		return nil, nil
+	}
+	sub := &Subscription{}
+	if err := json.Unmarshal(b, sub); err != nil {
+		return nil, fmt.Errorf("invalid subscription for %s in secret %s: %w", namespace, s.Name, err)
+	}
+	return sub, nil
+}
+
+func (s *SecretStore) List(ctx context.Context) ([]Subscription, error) {
+	secret, _, err := s.secret(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subs := make([]Subscription, 0, len(secret.Data))
+	for ns, b := range secret.Data {
+		var sub Subscription
+		if err := json.Unmarshal(b, &sub); err != nil {
+			return nil, fmt.Errorf("invalid subscription for %s in secret %s: %w", ns, s.Name, err)
+		}
+		subs = append(subs, sub)
+	}
+	slices.SortFunc(subs, func(a, b Subscription) int { return strings.Compare(a.Namespace, b.Namespace) })
+	return subs, nil
+}
+
+func (s *SecretStore) Put(ctx context.Context, sub Subscription) error {
+	b, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, func(data map[string][]byte) { data[sub.Namespace] = b })
+}
+
+func (s *SecretStore) Delete(ctx context.Context, namespace string) error {
+	return s.update(ctx, func(data map[string][]byte) { delete(data, namespace) })
+}
+
+func (s *SecretStore) update(ctx context.Context, mutate func(map[string][]byte)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, exists, err := s.secret(ctx)
+		if err != nil {
+			return err
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		mutate(secret.Data)
+
+		if !exists {
+			_, err = s.Clientset.CoreV1().Secrets(s.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+			return err
+		}
+		_, err = s.Clientset.CoreV1().Secrets(s.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+}
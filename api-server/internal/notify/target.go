@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// internalSuffixes are the domains only resolvable inside the cluster or the local network
+var internalSuffixes = []string{".localhost", ".local", ".internal", ".svc", ".cluster.local"}
+
+// sharedAddressSpace (RFC 6598) is routed inside carrier and cloud networks, often as pod or service CIDRs
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// checkHost rejects the webhook hosts that are not on the public internet: internal names and addresses
+func checkHost(host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return checkAddr(addr)
+	}
+	h := strings.ToLower(strings.TrimSuffix(host, "."))
+	if h == "localhost" || !strings.Contains(h, ".") {
+		return fmt.Errorf("webhook host %q is internal", host)
+	}
+	for _, suffix := range internalSuffixes {
+		if strings.HasSuffix(h, suffix) {
+			return fmt.Errorf("webhook host %q is internal", host)
+		}
+	}
+	return nil
+}
+
+// checkAddr rejects loopback, link-local, private, shared, multicast and unspecified addresses
+func checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr.IsPrivate() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("webhook address %s is internal", addr)
+	}
+	return nil
+}
+
+// publicClient only connects to public addresses, checked once the host is resolved so that a name validated on
+// save cannot be pointed at the cluster later. It ignores HTTP proxies, which would be the address checked.
+func publicClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			return checkAddr(addr)
+		},
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,728 @@
+package openapi
+
+import (
+	"api-server/internal/notify"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// Kind is a claim kind registered with the api-server (handler.KubeClient.GVRs and Kinds)
+type Kind struct {
+	Resource string // plural resource name, i.e. storage
+	Group    string
+	Version  string
+	Kind     string // claimNames.kind of the XRD, i.e. Storage; the title-cased resource when empty
+}
+
+// Name is the claim kind served under the resource, i.e. storage -> Storage
+func (k Kind) Name() string {
+	if k.Kind != "" {
+		return k.Kind
+	}
+	return cases.Title(language.English).String(k.Resource)
+}
+
+// Document is the subset of the OpenAPI 3.0 object model the api-server publishes
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type PathItem map[string]*Operation // keyed by lower-case HTTP method
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	MaxLength   int                `json:"maxLength,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+var errorResponses = map[string]Response{
+	"400":     {Description: "Invalid request", Content: jsonContent(ref("Error"))},
+	"default": {Description: "Unexpected error", Content: jsonContent(ref("Error"))},
+}
+
+func withErrors(rs map[string]Response) map[string]Response {
+	for code, r := range errorResponses {
+		rs[code] = r
+	}
+	return rs
+}
+
+// Build derives the OpenAPI document from the claim kinds registered with the api-server
+func Build(version string, kinds []Kind, regions []string) *Document {
+	kinds = slices.Clone(kinds)
+	slices.SortFunc(kinds, func(a, b Kind) int { return strings.Compare(a.Resource, b.Resource) })
+
+	types := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		types = append(types, k.Name())
+	}
+
+	name := &Schema{
+		Type:        "string",
+		Description: "Kubernetes DNS label",
+		Pattern:     `^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`,
+		MaxLength:   63,
+	}
+	region := &Schema{Type: "string", Enum: regions}
+	ttl := &Schema{Type: "string", Description: "Requested lifetime as a Go duration (i.e. 4h) within the bounds set for the kind, the kind's default when empty"}
+	tagValue := &Schema{Type: "string", Description: "Label value, unassigned when omitted unless the tag policy requires it", MaxLength: 63}
+	tags := &Schema{
+		Type:        "object",
+		Description: "Cost-allocation tags, copied into the AWS tags of the composed resources",
+		Properties:  map[string]*Schema{"team": tagValue, "project": tagValue, "cost-center": tagValue},
+	}
+	eventType := &Schema{Type: "string"}
+	for _, t := range notify.EventTypes {
+		eventType.Enum = append(eventType.Enum, string(t))
+	}
+
+	schemas := map[string]*Schema{
+		"ClaimRequest": {
+			Type:     "object",
+			Required: []string{"type", "name", "namespace", "region"},
+			Properties: map[string]*Schema{
+				"type":      {Type: "string", Enum: types},
+				"name":      name,
+				"namespace": {Type: "string"},
+				"region":    region,
+				"ttl":       ttl,
+				"tags":      tags,
+				"cluster":   {Type: "string", Description: "Target cluster, see /api/v1/clusters; the default cluster when empty"},
+			},
+		},
+		"Claim": {
+			Type:     "object",
+			Required: []string{"name", "kind", "namespace", "region", "status"},
+			Properties: map[string]*Schema{
+				"name":         {Type: "string"},
+				"kind":         {Type: "string", Enum: types},
+				"namespace":    {Type: "string"},
+				"region":       {Type: "string"},
+				"preset":       {Type: "string"},
+				"status":       {Type: "string", Enum: []string{"Ready", "NotReady", "Unknown"}},
+				"createdAt":    {Type: "string", Format: "date-time"},
+				"ttl":          {Type: "string", Description: "Lifetime of the claim as a Go duration"},
+				"expiresAt":    {Type: "string", Format: "date-time", Description: "When the claim-controller deletes the claim, absent until it recorded it or when the claim does not expire"},
+				"renewals":     {Type: "integer", Description: "How many times the claim was extended"},
+				"lastActivity": {Type: "string", Format: "date-time", Description: "When the claim was last viewed, extended, revealed or bumped by its workloads"},
+				"tags":         tags,
+				"conditions":   {Type: "array", Items: ref("Condition")},
+				"gitops":       ref("GitOpsStatus"),
+				"cluster":      {Type: "string", Description: "Cluster of the claim when the api-server manages several"},
+			},
+		},
+		"ClusterStatus": {
+			Type:     "object",
+			Required: []string{"name", "default", "healthy"},
+			Properties: map[string]*Schema{
+				"name":        {Type: "string"},
+				"environment": {Type: "string"},
+				"default":     {Type: "boolean", Description: "Targeted by requests that name no cluster"},
+				"healthy":     {Type: "boolean"},
+				"failures":    {Type: "array", Items: &Schema{Type: "string"}, Description: "Failed health checks"},
+			},
+		},
+		"GitOpsStatus": {
+			Type:        "object",
+			Description: "Set when the api-server commits claims to a GitOps repository instead of creating them",
+			Required:    []string{"path", "sync"},
+			Properties: map[string]*Schema{
+				"path":     {Type: "string", Description: "Manifest of the claim in the repository"},
+				"revision": {Type: "string", Description: "Last commit that changed the manifest"},
+				"sync":     {Type: "string", Enum: []string{"Pending", "Synced", "OutOfSync", "Pruning", "NotInGit"}},
+			},
+		},
+		"Condition": {
+			Type:     "object",
+			Required: []string{"type", "status"},
+			Properties: map[string]*Schema{
+				"type":               {Type: "string"},
+				"status":             {Type: "string", Enum: []string{"True", "False", "Unknown"}},
+				"reason":             {Type: "string"},
+				"message":            {Type: "string"},
+				"lastTransitionTime": {Type: "string", Format: "date-time"},
+			},
+		},
+		"UpdateClaimRequest": {
+			Type:       "object",
+			Required:   []string{"region"},
+			Properties: map[string]*Schema{"region": region},
+		},
+		"ExtendClaimRequest": {
+			Type:     "object",
+			Required: []string{"duration"},
+			Properties: map[string]*Schema{
+				"duration": {Type: "string", Description: "Positive Go duration added to the TTL, i.e. 1h"},
+			},
+		},
+		"CloneClaimRequest": {
+			Type:     "object",
+			Required: []string{"name"},
+			Properties: map[string]*Schema{
+				"name":      name,
+				"namespace": {Type: "string", Description: "Defaults to the namespace of the source claim"},
+				"region":    {Type: "string", Enum: regions, Description: "Defaults to the region of the source claim"},
+				"ttl":       {Type: "string", Description: "Defaults to the source's TTL when it is within the kind's bounds, else the kind's default"},
+				"tags":      {Type: "object", Description: "Merged over the tags of the source claim", Properties: tags.Properties},
+				"cluster":   {Type: "string", Description: "Defaults to the cluster of the source claim"},
+			},
+		},
+		"Estimate": {
+			Type:     "object",
+			Required: []string{"currency", "hourly", "ttl", "total"},
+			Properties: map[string]*Schema{
+				"currency": {Type: "string"},
+				"hourly":   {Type: "number"},
+				"ttl":      {Type: "string", Description: "TTL the total is computed over"},
+				"total":    {Type: "number", Description: "Cost over the whole TTL"},
+			},
+		},
+		"ClaimCost": {
+			Type:     "object",
+			Required: []string{"kind", "namespace", "name", "region", "hourly", "accrued"},
+			Properties: map[string]*Schema{
+				"kind":      {Type: "string", Enum: types},
+				"namespace": {Type: "string"},
+				"name":      {Type: "string"},
+				"region":    {Type: "string"},
+				"preset":    {Type: "string"},
+				"hourly":    {Type: "number"},
+				"accrued":   {Type: "number", Description: "Cost since the claim was created"},
+			},
+		},
+		"NamespaceCost": {
+			Type:     "object",
+			Required: []string{"namespace", "claims", "hourly", "accrued"},
+			Properties: map[string]*Schema{
+				"namespace": {Type: "string"},
+				"claims":    {Type: "integer"},
+				"hourly":    {Type: "number"},
+				"accrued":   {Type: "number"},
+			},
+		},
+		"CostReport": {
+			Type:     "object",
+			Required: []string{"currency", "claims", "namespaces", "unpriced"},
+			Properties: map[string]*Schema{
+				"currency":   {Type: "string"},
+				"claims":     {Type: "array", Items: ref("ClaimCost")},
+				"namespaces": {Type: "array", Items: ref("NamespaceCost")},
+				"unpriced":   {Type: "integer", Description: "Claims without a rate in the pricing table"},
+			},
+		},
+		"WebhookRequest": {
+			Type:     "object",
+			Required: []string{"url"},
+			Properties: map[string]*Schema{
+				"url":    {Type: "string", Format: "uri"},
+				"secret": {Type: "string", Description: "HMAC-SHA256 key of the X-Platform-Signature header, the current secret is kept when empty"},
+				"events": {Type: "array", Items: eventType, Description: "Every event when empty"},
+			},
+		},
+		"Webhook": {
+			Type:     "object",
+			Required: []string{"namespace", "url", "hasSecret"},
+			Properties: map[string]*Schema{
+				"namespace": {Type: "string"},
+				"url":       {Type: "string", Format: "uri"},
+				"hasSecret": {Type: "boolean"},
+				"events":    {Type: "array", Items: eventType},
+			},
+		},
+		"Event": {
+			Type:        "object",
+			Description: "Payload POSTed to webhooks",
+			Required:    []string{"id", "type", "time", "claim"},
+			Properties: map[string]*Schema{
+				"id":   {Type: "string"},
+				"type": eventType,
+				"time": {Type: "string", Format: "date-time"},
+				"claim": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"kind":      {Type: "string", Enum: types},
+						"namespace": {Type: "string"},
+						"name":      {Type: "string"},
+						"region":    {Type: "string"},
+						"status":    {Type: "string"},
+						"expiresAt": {Type: "string", Format: "date-time"},
+					},
+				},
+				"message": {Type: "string"},
+			},
+		},
+		"DeadLetter": {
+			Type:     "object",
+			Required: []string{"event", "url", "attempts", "error", "time"},
+			Properties: map[string]*Schema{
+				"event":    ref("Event"),
+				"url":      {Type: "string"},
+				"attempts": {Type: "integer"},
+				"error":    {Type: "string"},
+				"time":     {Type: "string", Format: "date-time"},
+			},
+		},
+		"Connection": {
+			Type:     "object",
+			Required: []string{"kind", "namespace", "name", "revealed", "details"},
+			Properties: map[string]*Schema{
+				"kind":      {Type: "string", Enum: types},
+				"namespace": {Type: "string"},
+				"name":      {Type: "string"},
+				"revealed":  {Type: "boolean"},
+				"details": {Type: "array", Items: &Schema{
+					Type:     "object",
+					Required: []string{"key", "value", "secret"},
+					Properties: map[string]*Schema{
+						"key":    {Type: "string"},
+						"value":  {Type: "string", Description: "Masked for credentials unless revealed"},
+						"secret": {Type: "boolean"},
+					},
+				}},
+				"env": {Type: "string", Description: "Shell exports of every detail, only when revealed"},
+			},
+		},
+		"Error": {
+			Type:     "object",
+			Required: []string{"code", "message"},
+			Properties: map[string]*Schema{
+				"code":    {Type: "integer"},
+				"message": {Type: "string"},
+			},
+		},
+	}
+
+	// Each registered kind is also described as the Kubernetes object the api-server creates, i.e. StorageClaim or
+	// ModelDeploymentClaim
+	for _, k := range kinds {
+		schemas[strings.TrimSuffix(k.Name(), "Claim")+"Claim"] = &Schema{
+			Type:        "object",
+			Description: fmt.Sprintf("Crossplane claim %s.%s/%s", k.Resource, k.Group, k.Version),
+			Required:    []string{"apiVersion", "kind", "metadata", "spec"},
+			Properties: map[string]*Schema{
+				"apiVersion": {Type: "string", Enum: []string{k.Group + "/" + k.Version}},
+				"kind":       {Type: "string", Enum: []string{k.Name()}},
+				"metadata": {
+					Type:     "object",
+					Required: []string{"name", "namespace"},
+					Properties: map[string]*Schema{
+						"name":      name,
+						"namespace": {Type: "string"},
+					},
+				},
+				"spec": {
+					Type:       "object",
+					Required:   []string{"location"},
+					Properties: map[string]*Schema{"location": region},
+				},
+			},
+		}
+	}
+
+	form := &Schema{
+		Type:     "object",
+		Required: []string{"type", "name", "username", "region"},
+		Properties: map[string]*Schema{
+			"type":            {Type: "string", Enum: types},
+			"name":            name,
+			"username":        {Type: "string", Description: "Namespace the claim is created in"},
+			"region":          region,
+			"ttl":             ttl,
+			"tag-team":        tagValue,
+			"tag-project":     tagValue,
+			"tag-cost-center": tagValue,
+		},
+	}
+	nsParam := Parameter{Name: "ns", In: "query", Required: true, Description: "Namespace of the claims", Schema: &Schema{Type: "string"}}
+	typeParam := Parameter{Name: "type", In: "query", Required: true, Description: "Claim kind", Schema: &Schema{Type: "string", Enum: types}}
+	clusterParam := Parameter{Name: "cluster", In: "query", Description: "Target cluster, see /api/v1/clusters; the default cluster when omitted", Schema: &Schema{Type: "string"}}
+	claimParams := []Parameter{
+		{Name: "type", In: "path", Required: true, Description: "Claim kind", Schema: &Schema{Type: "string", Enum: types}},
+		{Name: "namespace", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "name", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		clusterParam,
+	}
+	webhookParam := Parameter{Name: "namespace", In: "path", Required: true, Schema: &Schema{Type: "string"}}
+	notFound := func(rs map[string]Response) map[string]Response {
+		rs["404"] = Response{Description: "Claim or kind not found", Content: jsonContent(ref("Error"))}
+		return withErrors(rs)
+	}
+
+	healthParams := []Parameter{
+		{Name: "verbose", In: "query", Description: "List the result of every check", Schema: &Schema{Type: "boolean"}},
+		{Name: "exclude", In: "query", Description: "Skip a check by name", Schema: &Schema{Type: "string"}},
+	}
+	healthResponses := map[string]Response{
+		"200": {Description: "All checks passed"},
+		"500": {Description: "At least one check failed; the body lists every check"},
+	}
+
+	return &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: "Platform API", Version: version},
+		Paths: map[string]PathItem{
+			"/api/v1/claims": {
+				"get": {
+					OperationID: "listClaims",
+					Summary:     "List claims of a kind in a namespace, across every cluster unless one is given",
+					Tags:        []string{"claims"},
+					Parameters: []Parameter{nsParam, typeParam,
+						{Name: "cluster", In: "query", Description: "Only list this cluster", Schema: &Schema{Type: "string"}}},
+					Responses: withErrors(map[string]Response{
+						"200": {Description: "Claims", Content: jsonContent(&Schema{Type: "array", Items: ref("Claim")})},
+					}),
+				},
+				"post": {
+					OperationID: "createClaim",
+					Summary:     "Create a claim",
+					Tags:        []string{"claims"},
+					RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("ClaimRequest"))},
+					Responses: withErrors(map[string]Response{
+						"201": {Description: "Claim created", Content: jsonContent(ref("Claim"))},
+					}),
+				},
+			},
+			"/api/v1/claims/{type}/{namespace}/{name}": {
+				"get": {
+					OperationID: "getClaim",
+					Summary:     "Describe a claim",
+					Tags:        []string{"claims"},
+					Parameters:  claimParams,
+					Responses: notFound(map[string]Response{
+						"200": {Description: "Claim", Content: jsonContent(ref("Claim"))},
+					}),
+				},
+				"patch": {
+					OperationID: "updateClaim",
+					Summary:     "Edit a claim",
+					Tags:        []string{"claims"},
+					Parameters:  claimParams,
+					RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("UpdateClaimRequest"))},
+					Responses: notFound(map[string]Response{
+						"200": {Description: "Claim updated", Content: jsonContent(ref("Claim"))},
+					}),
+				},
+				"delete": {
+					OperationID: "deleteClaim",
+					Summary:     "Delete a claim",
+					Tags:        []string{"claims"},
+					Parameters:  claimParams,
+					Responses:   notFound(map[string]Response{"204": {Description: "Claim deleted"}}),
+				},
+			},
+			"/api/v1/claims/{type}/{namespace}/{name}/extend": {
+				"post": {
+					OperationID: "extendClaim",
+					Summary:     "Extend the TTL of a claim, within the renewals and maximum lifetime of its kind",
+					Tags:        []string{"claims"},
+					Parameters:  claimParams,
+					RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("ExtendClaimRequest"))},
+					Responses: notFound(map[string]Response{
+						"200": {Description: "Claim extended", Content: jsonContent(ref("Claim"))},
+						"409": {Description: "Renewal limit or maximum lifetime reached", Content: jsonContent(ref("Error"))},
+					}),
+				},
+			},
+			"/api/v1/claims/{type}/{namespace}/{name}/activity": {
+				"post": {
+					OperationID: "touchClaim",
+					Summary:     "Record activity on a claim, the heartbeat of workloads so it is not deleted as idle",
+					Tags:        []string{"claims"},
+					Parameters:  claimParams,
+					Responses:   notFound(map[string]Response{"204": {Description: "Activity recorded"}}),
+				},
+			},
+			"/api/v1/claims/{type}/{namespace}/{name}/connection": {
+				"get": {
+					OperationID: "getConnection",
+					Summary:     "Connection details of a Ready claim, credentials masked unless revealed (audited)",
+					Tags:        []string{"claims"},
+					Parameters: append(claimParams,
+						Parameter{Name: "reveal", In: "query", Description: "true to unmask credentials", Schema: &Schema{Type: "boolean"}}),
+					Responses: notFound(map[string]Response{
+						"200": {Description: "Connection details", Content: jsonContent(ref("Connection"))},
+						"403": {Description: "Not the owner of the claim's namespace", Content: jsonContent(ref("Error"))},
+					}),
+				},
+			},
+			"/api/v1/claims/{type}/{namespace}/{name}/clone": {
+				"post": {
+					OperationID: "cloneClaim",
+					Summary:     "Submit a copy of a live claim under a new name, validated like a new claim",
+					Tags:        []string{"claims"},
+					Parameters:  claimParams,
+					RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("CloneClaimRequest"))},
+					Responses: notFound(map[string]Response{
+						"201": {Description: "Clone created", Content: jsonContent(ref("Claim"))},
+					}),
+				},
+			},
+			"/api/v1/clusters": {
+				"get": {
+					OperationID: "listClusters",
+					Summary:     "Clusters claims can target with their health, empty when the api-server manages a single cluster",
+					Tags:        []string{"clusters"},
+					Responses: withErrors(map[string]Response{
+						"200": {Description: "Clusters, the default first", Content: jsonContent(&Schema{Type: "array", Items: ref("ClusterStatus")})},
+					}),
+				},
+			},
+			"/api/v1/estimate": {
+				"get": {
+					OperationID: "estimateCost",
+					Summary:     "Estimate the cost of a claim before submitting it",
+					Tags:        []string{"costs"},
+					Parameters: []Parameter{
+						typeParam,
+						{Name: "region", In: "query", Required: true, Schema: region},
+						{Name: "preset", In: "query", Schema: &Schema{Type: "string"}},
+						{Name: "ttl", In: "query", Description: "Go duration, defaults to the claim-controller TTL", Schema: &Schema{Type: "string"}},
+					},
+					Responses: withErrors(map[string]Response{
+						"200": {Description: "Estimate", Content: jsonContent(ref("Estimate"))},
+						"404": {Description: "No rate in the pricing table", Content: jsonContent(ref("Error"))},
+					}),
+				},
+			},
+			"/api/v1/costs": {
+				"get": {
+					OperationID: "getCosts",
+					Summary:     "Accrued cost of live claims, per claim and per namespace",
+					Tags:        []string{"costs"},
+					Parameters: []Parameter{
+						{Name: "namespace", In: "query", Description: "Only report this namespace", Schema: &Schema{Type: "string"}},
+					},
+					Responses: withErrors(map[string]Response{
+						"200": {Description: "Costs", Content: jsonContent(ref("CostReport"))},
+						"503": {Description: "Claim caches not synced yet", Content: jsonContent(ref("Error"))},
+					}),
+				},
+			},
+			"/api/v1/webhooks/{namespace}": {
+				"get": {
+					OperationID: "getWebhook",
+					Summary:     "Describe the webhook of a namespace",
+					Tags:        []string{"notifications"},
+					Parameters:  []Parameter{webhookParam},
+					Responses: withErrors(map[string]Response{
+						"200": {Description: "Webhook", Content: jsonContent(ref("Webhook"))},
+						"404": {Description: "No webhook registered", Content: jsonContent(ref("Error"))},
+					}),
+				},
+				"put": {
+					OperationID: "putWebhook",
+					Summary:     "Register or replace the webhook of a namespace",
+					Tags:        []string{"notifications"},
+					Parameters:  []Parameter{webhookParam},
+					RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("WebhookRequest"))},
+					Responses: withErrors(map[string]Response{
+						"200": {Description: "Webhook saved", Content: jsonContent(ref("Webhook"))},
+					}),
+				},
+				"delete": {
+					OperationID: "deleteWebhook",
+					Summary:     "Stop notifications for a namespace",
+					Tags:        []string{"notifications"},
+					Parameters:  []Parameter{webhookParam},
+					Responses:   withErrors(map[string]Response{"204": {Description: "Webhook removed"}}),
+				},
+			},
+			"/api/v1/webhooks/{namespace}/deadletters": {
+				"get": {
+					OperationID: "listDeadLetters",
+					Summary:     "Events that could not be delivered after every retry",
+					Tags:        []string{"notifications"},
+					Parameters:  []Parameter{webhookParam},
+					Responses: withErrors(map[string]Response{
+						"200": {Description: "Dead letters, oldest first", Content: jsonContent(&Schema{Type: "array", Items: ref("DeadLetter")})},
+					}),
+				},
+			},
+			"/webhooks": {
+				"get": {
+					OperationID: "viewWebhooks",
+					Summary:     "Render the notification settings of a namespace",
+					Tags:        []string{"ui"},
+					Parameters:  []Parameter{nsParam},
+					Responses:   map[string]Response{"200": {Description: "HTML page"}},
+				},
+				"post": {
+					OperationID: "saveWebhook",
+					Summary:     "Save or remove the webhook from the HTML form",
+					Tags:        []string{"ui"},
+					RequestBody: &RequestBody{Required: true, Content: map[string]MediaType{"application/x-www-form-urlencoded": {Schema: &Schema{
+						Type:     "object",
+						Required: []string{"namespace"},
+						Properties: map[string]*Schema{
+							"namespace": {Type: "string"},
+							"url":       {Type: "string", Format: "uri"},
+							"secret":    {Type: "string"},
+							"events":    {Type: "array", Items: eventType},
+							"action":    {Type: "string", Enum: []string{"save", "delete"}},
+						},
+					}}}},
+					Responses: map[string]Response{
+						"302": {Description: "Redirect to the notification settings"},
+						"400": {Description: "Invalid request"},
+					},
+				},
+			},
+			"/view/{name}": {
+				"get": {
+					OperationID: "viewClaim",
+					Summary:     "Render the detail page of a claim, including its cost",
+					Tags:        []string{"ui"},
+					Parameters: []Parameter{
+						{Name: "name", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+						nsParam,
+						typeParam,
+					},
+					Responses: map[string]Response{"200": {Description: "HTML page"}},
+				},
+			},
+			"/submit": {
+				"post": {
+					OperationID: "submitClaim",
+					Summary:     "Create a claim from the HTML form",
+					Tags:        []string{"ui"},
+					RequestBody: &RequestBody{Required: true, Content: map[string]MediaType{"application/x-www-form-urlencoded": {Schema: form}}},
+					Responses: map[string]Response{
+						"302": {Description: "Redirect to the claims list"},
+						"400": {Description: "Invalid request"},
+					},
+				},
+			},
+			"/claims": {
+				"get": {
+					OperationID: "viewClaims",
+					Summary:     "Render the claims list",
+					Tags:        []string{"ui"},
+					Parameters: []Parameter{
+						{Name: "ns", In: "query", Description: "Namespace of the claims (defaults to dev-user)", Schema: &Schema{Type: "string"}},
+						typeParam,
+					},
+					Responses: map[string]Response{"200": {Description: "HTML page"}},
+				},
+			},
+			"/openapi.json": {
+				"get": {
+					OperationID: "getOpenAPI",
+					Summary:     "This document",
+					Responses:   map[string]Response{"200": {Description: "OpenAPI 3 document"}},
+				},
+			},
+			"/livez": {
+				"get": {
+					OperationID: "getLivez",
+					Summary:     "Liveness probe",
+					Tags:        []string{"health"},
+					Parameters:  healthParams,
+					Responses:   healthResponses,
+				},
+			},
+			"/readyz": {
+				"get": {
+					OperationID: "getReadyz",
+					Summary:     "Readiness and startup probe",
+					Tags:        []string{"health"},
+					Parameters:  healthParams,
+					Responses:   healthResponses,
+				},
+			},
+			"/healthz": {
+				"get": {
+					OperationID: "getHealthz",
+					Summary:     "Every health check",
+					Tags:        []string{"health"},
+					Parameters:  healthParams,
+					Responses:   healthResponses,
+				},
+			},
+			"/metrics": {
+				"get": {
+					OperationID: "getMetrics",
+					Summary:     "Prometheus metrics",
+					Responses:   map[string]Response{"200": {Description: "Prometheus text exposition"}},
+				},
+			},
+		},
+		Components: Components{Schemas: schemas},
+	}
+}
+
+// Handler serves the document, rendered once
+func Handler(doc *Document) http.HandlerFunc {
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("Unable to render OpenAPI document: %v", err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+	}
+}
@@ -0,0 +1,41 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild_DerivesSchemasFromKinds(t *testing.T) {
+	doc := Build("test", []Kind{
+		{Resource: "storage", Group: "platform.example.org", Version: "v1alpha1"},
+		{Resource: "compute", Group: "platform.example.org", Version: "v1alpha1"},
+	}, []string{"US", "EU"})
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, []string{"Compute", "Storage"}, doc.Components.Schemas["ClaimRequest"].Properties["type"].Enum)
+
+	storage, ok := doc.Components.Schemas["StorageClaim"]
+	require.True(t, ok)
+	assert.Equal(t, []string{"platform.example.org/v1alpha1"}, storage.Properties["apiVersion"].Enum)
+	assert.Equal(t, []string{"US", "EU"}, storage.Properties["spec"].Properties["location"].Enum)
+	assert.Contains(t, doc.Components.Schemas, "ComputeClaim")
+
+	assert.Contains(t, doc.Paths["/api/v1/claims"], "get")
+	assert.Contains(t, doc.Paths["/api/v1/claims"], "post")
+}
+
+func TestHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Handler(Build("test", nil, nil))(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Equal(t, "Platform API", doc["info"].(map[string]any)["title"])
+}
@@ -0,0 +1,115 @@
+// Package pricing estimates the cost of claims from a local table of hourly rates, so engineers
+// see what a claim costs before submitting it without calling a cloud billing API
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ErrNoRate is returned when the table has no rate for a kind, preset and region
+var ErrNoRate = errors.New("no rate in the pricing table")
+
+// Rate is the hourly price of a claim kind in a region. An empty Preset is the rate used
+// for claims that do not choose a preset, or choose one the table does not list.
+type Rate struct {
+	Kind   string  `json:"kind"`
+	Preset string  `json:"preset,omitempty"`
+	Region string  `json:"region"`
+	Hourly float64 `json:"hourly"`
+}
+
+// Table is the pricing file, i.e. mounted from the api-server pricing ConfigMap:
+//
+//	currency: USD
+//	rates:
+//	  - kind: Storage
+//	    region: US
+//	    hourly: 0.023
+type Table struct {
+	Currency string `json:"currency"`
+	Rates    []Rate `json:"rates"`
+}
+
+// Estimate is the cost of a claim before it is created
+type Estimate struct {
+	Currency string  `json:"currency"`
+	Hourly   float64 `json:"hourly"`
+	TTL      string  `json:"ttl"`
+	Total    float64 `json:"total"` // Hourly over the whole TTL
+}
+
+// Load reads a YAML (or JSON) pricing table
+func Load(path string) (*Table, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading pricing table: %w", err)
+	}
+	t := &Table{}
+	if err := yaml.UnmarshalStrict(b, t); err != nil {
+		return nil, fmt.Errorf("error parsing pricing table %s: %w", path, err)
+	}
+	if t.Currency == "" {
+		t.Currency = "USD"
+	}
+	for _, r := range t.Rates {
+		if r.Kind == "" || r.Region == "" || r.Hourly < 0 {
+			return nil, fmt.Errorf("invalid rate in %s: %+v", path, r)
+		}
+	}
+	return t, nil
+}
+
+// Hourly looks up the rate of a claim, falling back to the kind's rate without a preset.
+// Kinds are matched case-insensitively so both storage and Storage resolve.
+func (t *Table) Hourly(kind, preset, region string) (float64, error) {
+	fallback, found := 0.0, false
+	for _, r := range t.Rates {
+		if !strings.EqualFold(r.Kind, kind) || r.Region != region {
+			continue
+		}
+		if r.Preset == preset {
+			return r.Hourly, nil
+		}
+		if r.Preset == "" {
+			fallback, found = r.Hourly, true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("%w: kind=%s preset=%s region=%s", ErrNoRate, kind, preset, region)
+	}
+	return fallback, nil
+}
+
+// Estimate is the hourly cost of a claim and its cost over ttl, after which the claim-controller deletes it
+func (t *Table) Estimate(kind, preset, region string, ttl time.Duration) (Estimate, error) {
+	hourly, err := t.Hourly(kind, preset, region)
+	if err != nil {
+		return Estimate{}, err
+	}
+	return Estimate{
+		Currency: t.Currency,
+		Hourly:   hourly,
+		TTL:      ttl.String(),
+		Total:    Round(hourly * ttl.Hours()),
+	}, nil
+}
+
+// Accrued is the cost of a claim between its creation and now
+func Accrued(hourly float64, created, now time.Time) float64 {
+	if created.IsZero() || now.Before(created) {
+		return 0
+	}
+	return Round(hourly * now.Sub(created).Hours())
+}
+
+// Round to a hundredth of a cent, enough precision for short-lived claims
+func Round(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
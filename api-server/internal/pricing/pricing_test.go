@@ -0,0 +1,64 @@
+package pricing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rates:
+  - kind: Storage
+    region: US
+    hourly: 0.02
+  - kind: Compute
+    region: US
+    hourly: 0.1
+  - kind: Compute
+    preset: gpu
+    region: US
+    hourly: 1.2
+`), 0o644))
+
+	table, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", table.Currency)
+
+	hourly, err := table.Hourly("compute", "gpu", "US")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.2, hourly)
+
+	// unknown presets fall back to the kind's default rate
+	hourly, err = table.Hourly("Compute", "large", "US")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.1, hourly)
+
+	_, err = table.Hourly("Storage", "", "EU")
+	assert.ErrorIs(t, err, ErrNoRate)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rates:\n  - kind: Storage\n    hourly: 1\n"), 0o644))
+
+	_, err := Load(path)
+	assert.ErrorContains(t, err, "invalid rate")
+}
+
+func TestEstimate(t *testing.T) {
+	table := &Table{Currency: "EUR", Rates: []Rate{{Kind: "Storage", Region: "EU", Hourly: 0.03}}}
+
+	est, err := table.Estimate("storage", "", "EU", 10*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, Estimate{Currency: "EUR", Hourly: 0.03, TTL: "10m0s", Total: 0.005}, est)
+
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 0.09, Accrued(0.03, created, created.Add(3*time.Hour)))
+	assert.Zero(t, Accrued(0.03, time.Time{}, created))
+}
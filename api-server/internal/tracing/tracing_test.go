@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMiddleware_NamesSpanAfterRoutePattern(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent string
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/api/v1/claims/{type}/{namespace}/{name}", func(w http.ResponseWriter, r *http.Request) {
+		traceparent = Traceparent(r.Context())
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims/storage/dev/mystorage", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /api/v1/claims/{type}/{namespace}/{name}", spans[0].Name())
+	// continues the caller's trace and hands it on to the claim annotation
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rr.Header().Get("X-Trace-Id"))
+	assert.Contains(t, traceparent, "4bf92f3577b34da6a3ce929d0e0e4736")
+}
+
+func TestTraceID_Empty(t *testing.T) {
+	assert.Empty(t, TraceID(context.Background()))
+	assert.Empty(t, Traceparent(context.Background()))
+}
@@ -0,0 +1,430 @@
+// Package client is a typed Go client for the platform api-server JSON API (see /openapi.json)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// ClaimRequest mirrors the ClaimRequest schema
+type ClaimRequest struct {
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Region    string            `json:"region"`
+	TTL       string            `json:"ttl,omitempty"`     // Go duration, the kind's default when empty
+	Tags      map[string]string `json:"tags,omitempty"`    // team, project and cost-center
+	Cluster   string            `json:"cluster,omitempty"` // the client's cluster, or the server default, when empty
+}
+
+// CloneClaimRequest mirrors the CloneClaimRequest schema; empty fields are copied from the source claim
+type CloneClaimRequest struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Region    string            `json:"region,omitempty"`
+	TTL       string            `json:"ttl,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Cluster   string            `json:"cluster,omitempty"`
+}
+
+// Claim mirrors the Claim schema
+type Claim struct {
+	Name         string            `json:"name"`
+	Kind         string            `json:"kind"`
+	Namespace    string            `json:"namespace"`
+	Region       string            `json:"region"`
+	Preset       string            `json:"preset,omitempty"`
+	Status       string            `json:"status"`
+	CreatedAt    time.Time         `json:"createdAt,omitzero"`
+	TTL          string            `json:"ttl,omitempty"`
+	ExpiresAt    time.Time         `json:"expiresAt,omitzero"`
+	Renewals     int               `json:"renewals,omitempty"`
+	LastActivity time.Time         `json:"lastActivity,omitzero"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	Conditions   []Condition       `json:"conditions,omitempty"`
+	GitOps       *GitOpsStatus     `json:"gitops,omitempty"`
+	Cluster      string            `json:"cluster,omitempty"`
+}
+
+// GitOpsStatus mirrors the GitOpsStatus schema
+type GitOpsStatus struct {
+	Path     string `json:"path"`
+	Revision string `json:"revision,omitempty"`
+	Sync     string `json:"sync"`
+}
+
+// ClusterStatus mirrors the ClusterStatus schema
+type ClusterStatus struct {
+	Name        string   `json:"name"`
+	Environment string   `json:"environment,omitempty"`
+	Default     bool     `json:"default"`
+	Healthy     bool     `json:"healthy"`
+	Failures    []string `json:"failures,omitempty"`
+}
+
+// Condition mirrors the Condition schema
+type Condition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime,omitzero"`
+}
+
+// Ready reports whether the claim's Ready condition is True
+func (c *Claim) Ready() bool {
+	return c.Status == "Ready"
+}
+
+// Estimate mirrors the Estimate schema
+type Estimate struct {
+	Currency string  `json:"currency"`
+	Hourly   float64 `json:"hourly"`
+	TTL      string  `json:"ttl"`
+	Total    float64 `json:"total"`
+}
+
+// CostReport mirrors the CostReport schema
+type CostReport struct {
+	Currency   string          `json:"currency"`
+	Claims     []ClaimCost     `json:"claims"`
+	Namespaces []NamespaceCost `json:"namespaces"`
+	Unpriced   int             `json:"unpriced"`
+}
+
+// ClaimCost mirrors the ClaimCost schema
+type ClaimCost struct {
+	Kind      string  `json:"kind"`
+	Namespace string  `json:"namespace"`
+	Name      string  `json:"name"`
+	Region    string  `json:"region"`
+	Preset    string  `json:"preset,omitempty"`
+	Hourly    float64 `json:"hourly"`
+	Accrued   float64 `json:"accrued"`
+}
+
+// NamespaceCost mirrors the NamespaceCost schema
+type NamespaceCost struct {
+	Namespace string  `json:"namespace"`
+	Claims    int     `json:"claims"`
+	Hourly    float64 `json:"hourly"`
+	Accrued   float64 `json:"accrued"`
+}
+
+// Connection mirrors the Connection schema
+type Connection struct {
+	Kind      string             `json:"kind"`
+	Namespace string             `json:"namespace"`
+	Name      string             `json:"name"`
+	Revealed  bool               `json:"revealed"`
+	Details   []ConnectionDetail `json:"details"`
+	Env       string             `json:"env,omitempty"`
+}
+
+// ConnectionDetail is one key of a Connection
+type ConnectionDetail struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret"`
+}
+
+// Error is returned for every non-2xx response and mirrors the Error schema
+type Error struct {
+	StatusCode int    `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api-server returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an *Error with status 404
+func IsNotFound(err error) bool { return hasStatus(err, http.StatusNotFound) }
+
+// IsInvalid reports whether err is an *Error with status 400
+func IsInvalid(err error) bool { return hasStatus(err, http.StatusBadRequest) }
+
+// IsAlreadyExists reports whether err is an *Error with status 409
+func IsAlreadyExists(err error) bool { return hasStatus(err, http.StatusConflict) }
+
+func hasStatus(err error, code int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == code
+}
+
+// Client talks to a single api-server
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	maxRetries int
+	backoff    time.Duration
+	cluster    string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient
+func WithHTTPClient(c *http.Client) Option {
+	return func(cl *Client) { cl.httpClient = c }
+}
+
+// WithToken sends the token as a bearer Authorization header
+func WithToken(token string) Option {
+	return func(cl *Client) { cl.token = token }
+}
+
+// WithRetries sets how many times a request is retried after a network error or a 429/502/503/504,
+// waiting backoff, 2*backoff, 4*backoff, ... between attempts
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(cl *Client) {
+		cl.maxRetries = n
+		cl.backoff = backoff
+	}
+}
+
+// WithCluster targets every request at a cluster of a multi-cluster api-server, see Clusters
+func WithCluster(name string) Option {
+	return func(cl *Client) { cl.cluster = name }
+}
+
+// New returns a Client for the api-server at baseURL, i.e. http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid api-server URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid api-server URL %q: scheme and host are required", baseURL)
+	}
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		maxRetries: 3,
+		backoff:    200 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// CreateClaim creates a claim and returns it as accepted by the api-server
+func (c *Client) CreateClaim(ctx context.Context, req ClaimRequest) (*Claim, error) {
+	var claim Claim
+	if err := c.do(ctx, http.MethodPost, "/api/v1/claims", nil, req, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// ListClaims lists the claims of a kind (i.e. Storage) in a namespace
+func (c *Client) ListClaims(ctx context.Context, namespace, kind string) ([]Claim, error) {
+	q := url.Values{"ns": {namespace}, "type": {kind}}
+	var claims []Claim
+	if err := c.do(ctx, http.MethodGet, "/api/v1/claims", q, nil, &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// GetClaim describes a single claim
+func (c *Client) GetClaim(ctx context.Context, kind, namespace, name string) (*Claim, error) {
+	var claim Claim
+	if err := c.do(ctx, http.MethodGet, claimPath(kind, namespace, name), nil, nil, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// UpdateClaim moves a claim to another region
+func (c *Client) UpdateClaim(ctx context.Context, kind, namespace, name, region string) (*Claim, error) {
+	var claim Claim
+	body := map[string]string{"region": region}
+	if err := c.do(ctx, http.MethodPatch, claimPath(kind, namespace, name), nil, body, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// ExtendClaim adds by to the TTL of a claim
+func (c *Client) ExtendClaim(ctx context.Context, kind, namespace, name string, by time.Duration) (*Claim, error) {
+	var claim Claim
+	body := map[string]string{"duration": by.String()}
+	if err := c.do(ctx, http.MethodPost, claimPath(kind, namespace, name)+"/extend", nil, body, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// CloneClaim submits a copy of a live claim
+func (c *Client) CloneClaim(ctx context.Context, kind, namespace, name string, req CloneClaimRequest) (*Claim, error) {
+	var claim Claim
+	if err := c.do(ctx, http.MethodPost, claimPath(kind, namespace, name)+"/clone", nil, req, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// DeleteClaim deletes a claim
+func (c *Client) DeleteClaim(ctx context.Context, kind, namespace, name string) error {
+	return c.do(ctx, http.MethodDelete, claimPath(kind, namespace, name), nil, nil, nil)
+}
+
+// TouchClaim records activity on a claim, so it is not deleted as idle
+func (c *Client) TouchClaim(ctx context.Context, kind, namespace, name string) error {
+	return c.do(ctx, http.MethodPost, claimPath(kind, namespace, name)+"/activity", nil, nil, nil)
+}
+
+// Connection returns the connection details of a Ready claim, credentials masked unless reveal
+func (c *Client) Connection(ctx context.Context, kind, namespace, name string, reveal bool) (*Connection, error) {
+	q := url.Values{}
+	if reveal {
+		q.Set("reveal", "true")
+	}
+	var conn Connection
+	if err := c.do(ctx, http.MethodGet, claimPath(kind, namespace, name)+"/connection", q, nil, &conn); err != nil {
+		return nil, err
+	}
+	return &conn, nil
+}
+
+// Clusters lists the clusters claims can target with their health, empty when the api-server manages one
+func (c *Client) Clusters(ctx context.Context) ([]ClusterStatus, error) {
+	var clusters []ClusterStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/clusters", nil, nil, &clusters); err != nil {
+		return nil, err
+	}
+	return clusters, nil
+}
+
+// Estimate prices a claim of kind in region over ttl before it is created, zero ttl uses the server default
+func (c *Client) Estimate(ctx context.Context, kind, region string, ttl time.Duration) (*Estimate, error) {
+	q := url.Values{"type": {kind}, "region": {region}}
+	if ttl > 0 {
+		q.Set("ttl", ttl.String())
+	}
+	var est Estimate
+	if err := c.do(ctx, http.MethodGet, "/api/v1/estimate", q, nil, &est); err != nil {
+		return nil, err
+	}
+	return &est, nil
+}
+
+// Costs reports the accrued cost of live claims in namespace, or in every namespace when empty
+func (c *Client) Costs(ctx context.Context, namespace string) (*CostReport, error) {
+	q := url.Values{}
+	if namespace != "" {
+		q.Set("namespace", namespace)
+	}
+	var report CostReport
+	if err := c.do(ctx, http.MethodGet, "/api/v1/costs", q, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func claimPath(kind, namespace, name string) string {
+	// escaped by url.URL.JoinPath
+	return path.Join("/api/v1/claims", kind, namespace, name)
+}
+
+func (c *Client) do(ctx context.Context, method, p string, query url.Values, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("error encoding request: %w", err)
+		}
+	}
+
+	u := c.baseURL.JoinPath(p)
+	if c.cluster != "" {
+		query = maps.Clone(query)
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("cluster", c.cluster)
+	}
+	u.RawQuery = query.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.backoff << (attempt - 1)):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("error building request: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+		if in != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+
+		retry, err := decode(resp, out)
+		if !retry {
+			return err
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", c.maxRetries+1, lastErr)
+}
+
+// decode reads the response into out, reporting whether the request may be retried
+func decode(resp *http.Response, out any) (bool, error) {
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, fmt.Errorf("error reading response: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if out == nil || len(b) == 0 {
+			return false, nil
+		}
+		if err := json.Unmarshal(b, out); err != nil {
+			return false, fmt.Errorf("error decoding response: %w", err)
+		}
+		return false, nil
+	}
+
+	apiErr := &Error{}
+	if err := json.Unmarshal(b, apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(b))
+	}
+	apiErr.StatusCode = resp.StatusCode
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true, apiErr
+	}
+	return false, apiErr
+}
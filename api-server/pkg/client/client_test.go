@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, h http.HandlerFunc) *Client {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL, WithRetries(2, time.Millisecond))
+	require.NoError(t, err)
+	return c
+}
+
+func TestCreateClaim(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/claims", r.URL.Path)
+		var req ClaimRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(Claim{Name: req.Name, Kind: "Storage", Namespace: req.Namespace, Region: req.Region, Status: "Unknown"})
+	})
+
+	claim, err := c.CreateClaim(context.Background(), ClaimRequest{Type: "Storage", Name: "mystorage", Namespace: "dev", Region: "US"})
+	require.NoError(t, err)
+	assert.Equal(t, "mystorage", claim.Name)
+	assert.Equal(t, "Storage", claim.Kind)
+}
+
+func TestTypedErrors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":400,"message":"Invalid region"}`))
+	})
+
+	_, err := c.ListClaims(context.Background(), "dev", "Storage")
+	require.Error(t, err)
+	assert.True(t, IsInvalid(err))
+	assert.False(t, IsNotFound(err))
+	assert.Contains(t, err.Error(), "Invalid region")
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name":"a"}]`))
+	})
+
+	claims, err := c.ListClaims(context.Background(), "dev", "Storage")
+	require.NoError(t, err)
+	assert.Len(t, claims, 1)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, err := c.ListClaims(context.Background(), "dev", "Storage")
+	require.Error(t, err)
+	assert.True(t, hasStatus(err, http.StatusBadGateway))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestContextCancelled(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	c.backoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.ListClaims(ctx, "dev", "Storage")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClaimOperations(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/claims/Storage/dev/mystorage":
+			_, _ = w.Write([]byte(`{"name":"mystorage","status":"Ready","conditions":[{"type":"Ready","status":"True"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/claims/Storage/dev/mystorage/extend":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "1h0m0s", body["duration"])
+			_, _ = w.Write([]byte(`{"name":"mystorage","ttl":"1h10m0s"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/claims/Storage/dev/mystorage/activity":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":404,"message":"not found"}`))
+		}
+	})
+	ctx := context.Background()
+
+	claim, err := c.GetClaim(ctx, "Storage", "dev", "mystorage")
+	require.NoError(t, err)
+	assert.True(t, claim.Ready())
+	assert.Len(t, claim.Conditions, 1)
+
+	claim, err = c.ExtendClaim(ctx, "Storage", "dev", "mystorage", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "1h10m0s", claim.TTL)
+
+	require.NoError(t, c.TouchClaim(ctx, "Storage", "dev", "mystorage"))
+	assert.True(t, IsNotFound(c.TouchClaim(ctx, "Storage", "dev", "missing")))
+
+	require.NoError(t, c.DeleteClaim(ctx, "Storage", "dev", "mystorage"))
+
+	_, err = c.GetClaim(ctx, "Storage", "dev", "missing")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestEstimate(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/estimate", r.URL.Path)
+		assert.Equal(t, "region=US&ttl=4h0m0s&type=Storage", r.URL.RawQuery)
+		_ = json.NewEncoder(w).Encode(Estimate{Currency: "USD", Hourly: 0.5, TTL: "4h0m0s", Total: 2})
+	})
+
+	est, err := c.Estimate(context.Background(), "Storage", "US", 4*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, est.Total)
+}
+
+func TestWithCluster(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "staging", r.URL.Query().Get("cluster"))
+		if r.URL.Path == "/api/v1/clusters" {
+			_, _ = w.Write([]byte(`[{"name":"dev","default":true,"healthy":true},{"name":"staging","default":false,"healthy":false,"failures":["kube-apiserver: timeout"]}]`))
+			return
+		}
+		assert.Equal(t, "dev", r.URL.Query().Get("ns"))
+		_, _ = w.Write([]byte(`[{"name":"a","cluster":"staging"}]`))
+	}))
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL, WithCluster("staging"))
+	require.NoError(t, err)
+
+	claims, err := c.ListClaims(context.Background(), "dev", "Storage")
+	require.NoError(t, err)
+	assert.Equal(t, "staging", claims[0].Cluster)
+
+	clusters, err := c.Clusters(context.Background())
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+	assert.True(t, clusters[0].Default)
+	assert.Equal(t, []string{"kube-apiserver: timeout"}, clusters[1].Failures)
+}
@@ -0,0 +1,84 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClaimLifecyclePolicySpec selects claims and defines how long they live. A claim matched by several policies
+// follows the most specific one: a policy naming its namespace beats one naming its kind, which beats one
+// only selecting its labels.
+type ClaimLifecyclePolicySpec struct {
+	// Kinds of the claims the policy applies to (i.e. Storage), every kind when empty
+	// +optional
+	Kinds []string `json:"kinds,omitempty"`
+	// Namespaces the policy applies to, every namespace when empty
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selector matches the labels of the claims the policy applies to, every claim when empty
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// DefaultTTL is the lifetime of claims without a platform.example.org/ttl annotation
+	// +optional
+	DefaultTTL *metav1.Duration `json:"defaultTTL,omitempty"`
+	// MaxTTL clamps the lifetime of claims, requested or default
+	// +optional
+	MaxTTL *metav1.Duration `json:"maxTTL,omitempty"`
+	// GracePeriod is how long expired claims hibernate, paused or scaled down, before they are deleted.
+	// Extending the TTL of a hibernated claim revives it.
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+	// IdleTimeout expires claims without activity (platform.example.org/last-activity) for this long, before
+	// their TTL. Zero disables it.
+	// +optional
+	IdleTimeout *metav1.Duration `json:"idleTimeout,omitempty"`
+	// Exemptions match the labels of claims that never expire
+	// +optional
+	Exemptions []metav1.LabelSelector `json:"exemptions,omitempty"`
+	// Schedule pauses claims outside its active windows and resumes them inside, unless they have
+	// platform.example.org/schedule-* annotations of their own
+	// +optional
+	Schedule *ClaimSchedule `json:"schedule,omitempty"`
+}
+
+// ClaimSchedule bounds the active windows of claims with cron expressions, i.e. from 0 8 * * mon-fri to
+// 0 18 * * mon-fri
+type ClaimSchedule struct {
+	// Start is the cron expression starting active windows
+	Start string `json:"start"`
+	// Stop is the cron expression stopping active windows
+	Stop string `json:"stop"`
+	// TimeZone of the cron expressions as an IANA name (i.e. Europe/Paris), UTC when empty
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=clp
+// +kubebuilder:printcolumn:name="Kinds",type=string,JSONPath=`.spec.kinds`
+// +kubebuilder:printcolumn:name="Namespaces",type=string,JSONPath=`.spec.namespaces`
+// +kubebuilder:printcolumn:name="Default TTL",type=string,JSONPath=`.spec.defaultTTL`
+// +kubebuilder:printcolumn:name="Max TTL",type=string,JSONPath=`.spec.maxTTL`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClaimLifecyclePolicy defines the expiry rules of the claims it selects. Policies are cluster-scoped so the
+// platform team, not tenants, owns the lifetime of claims in tenant namespaces.
+type ClaimLifecyclePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClaimLifecyclePolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClaimLifecyclePolicyList contains a list of ClaimLifecyclePolicy
+type ClaimLifecyclePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClaimLifecyclePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClaimLifecyclePolicy{}, &ClaimLifecyclePolicyList{})
+}
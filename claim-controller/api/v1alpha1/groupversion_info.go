@@ -0,0 +1,19 @@
+// Package v1alpha1 contains the API types of the claim-controller
+// +kubebuilder:object:generate=true
+// +groupName=platform.example.org
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is the group of the claims the controller expires
+	GroupVersion = schema.GroupVersion{Group: "platform.example.org", Version: "v1alpha1"}
+
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types of this group-version to a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
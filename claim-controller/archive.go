@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"claim-controller/archive"
+	"claim-controller/controllers"
+)
+
+const archiveUsage = `Usage: claim-controller archive [--archive configmap:<namespace>|dir:<path>] <command>
+
+Commands:
+  list           list the archived claims, newest first
+  show <id>      print an archive: the claim, its composite and composed resources
+  restore <id>   recreate the archived claim, its TTL (extended, if it was) starting over
+`
+
+// runArchive inspects and restores the claims archived before deletion, i.e. with
+// kubectl exec deploy/claim-controller -- ./claim-controller archive list
+func runArchive(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("archive", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(fs.Output(), archiveUsage) }
+	spec := fs.String("archive", os.Getenv("CLAIM_ARCHIVE"), "The archive backend, defaults to $CLAIM_ARCHIVE.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("missing command")
+	}
+
+	ctx := context.Background()
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	store, err := archive.Open(*spec, c)
+	if err != nil {
+		return err
+	}
+
+	switch cmd := fs.Arg(0); cmd {
+	case "list":
+		entries, err := store.List(ctx)
+		if err != nil {
+			return err
+		}
+		slices.SortFunc(entries, func(a, b archive.Entry) int { return b.ArchivedAt.Compare(a.ArchivedAt) })
+		tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tKIND\tNAMESPACE\tNAME\tARCHIVED")
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.ID, e.Kind, e.Namespace, e.Name, e.ArchivedAt.Local().Format(time.RFC3339))
+		}
+		return tw.Flush()
+	case "show", "restore":
+		if fs.NArg() != 2 {
+			return fmt.Errorf("usage: claim-controller archive %s <id>", cmd)
+		}
+		rec, err := store.Get(ctx, fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		if cmd == "show" {
+			b, err := yaml.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			_, err = stdout.Write(b)
+			return err
+		}
+		claim, err := controllers.RestoreClaim(ctx, c, rec)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "restored %s %s/%s\n", claim.GetKind(), claim.GetNamespace(), claim.GetName())
+		return nil
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
@@ -0,0 +1,121 @@
+// Package archive keeps the manifests of claims the controller deletes, so they can be investigated or restored
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrNotFound is returned for an unknown archive ID
+var ErrNotFound = errors.New("archive not found")
+
+// Record is the archive of a deleted claim: the claim, its composite and the composed resources, status included
+type Record struct {
+	ID         string                       `json:"id"`
+	Kind       string                       `json:"kind"`
+	Namespace  string                       `json:"namespace"`
+	Name       string                       `json:"name"`
+	ArchivedAt time.Time                    `json:"archivedAt"`
+	Claim      *unstructured.Unstructured   `json:"claim"`
+	Composite  *unstructured.Unstructured   `json:"composite,omitempty"`
+	Composed   []*unstructured.Unstructured `json:"composed,omitempty"`
+}
+
+// Entry describes an archive without its manifests
+type Entry struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Namespace  string    `json:"namespace"`
+	Name       string    `json:"name"`
+	ArchivedAt time.Time `json:"archivedAt"`
+}
+
+// Store is an archive backend
+type Store interface {
+	Put(ctx context.Context, rec *Record) error
+	Get(ctx context.Context, id string) (*Record, error)
+	List(ctx context.Context) ([]Entry, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// NewRecord archives claim at now, its ID being unique per claim and second
+func NewRecord(claim, composite *unstructured.Unstructured, composed []*unstructured.Unstructured, now time.Time) *Record {
+	return &Record{
+		ID:         fmt.Sprintf("%s.%s.%s.%d", claim.GetNamespace(), strings.ToLower(claim.GetKind()), claim.GetName(), now.Unix()),
+		Kind:       claim.GetKind(),
+		Namespace:  claim.GetNamespace(),
+		Name:       claim.GetName(),
+		ArchivedAt: now.UTC().Truncate(time.Second),
+		Claim:      claim,
+		Composite:  composite,
+		Composed:   composed,
+	}
+}
+
+func (r *Record) entry() Entry {
+	return Entry{ID: r.ID, Kind: r.Kind, Namespace: r.Namespace, Name: r.Name, ArchivedAt: r.ArchivedAt}
+}
+
+func (r *Record) marshal() ([]byte, error) {
+	return yaml.Marshal(r)
+}
+
+func unmarshal(b []byte) (*Record, error) {
+	var rec Record
+	if err := yaml.Unmarshal(b, &rec); err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	return &rec, nil
+}
+
+// Retention bounds the archives kept, zero values meaning no limit
+type Retention struct {
+	MaxAge   time.Duration
+	MaxCount int
+}
+
+// Prune deletes the archives older than MaxAge and the oldest beyond MaxCount
+func (rt Retention) Prune(ctx context.Context, s Store, now time.Time) error {
+	if rt.MaxAge <= 0 && rt.MaxCount <= 0 {
+		return nil
+	}
+	entries, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return b.ArchivedAt.Compare(a.ArchivedAt) }) // newest first
+	var errs []error
+	for i, e := range entries {
+		if (rt.MaxCount > 0 && i >= rt.MaxCount) || (rt.MaxAge > 0 && now.Sub(e.ArchivedAt) > rt.MaxAge) {
+			if err := s.Delete(ctx, e.ID); err != nil && !errors.Is(err, ErrNotFound) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Open returns the backend described by spec: configmap:<namespace> stores one ConfigMap per archive in the
+// namespace, dir:<path> one file per archive in the directory (i.e. a PersistentVolume)
+func Open(spec string, c client.Client) (Store, error) {
+	backend, arg, ok := strings.Cut(spec, ":")
+	if !ok || arg == "" {
+		return nil, fmt.Errorf("invalid archive %q, expected configmap:<namespace> or dir:<path>", spec)
+	}
+	switch backend {
+	case "configmap":
+		return &ConfigMapStore{Client: c, Namespace: arg}, nil
+	case "dir":
+		return NewDirStore(arg)
+	default:
+		return nil, fmt.Errorf("unknown archive backend %q, expected configmap or dir", backend)
+	}
+}
@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newClaim(name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"spec":   map[string]any{"location": "EU"},
+		"status": map[string]any{"conditions": []any{map[string]any{"type": "Ready", "status": "True"}}},
+	}}
+	u.SetAPIVersion("platform.example.org/v1alpha1")
+	u.SetKind("Storage")
+	u.SetNamespace("alice")
+	u.SetName(name)
+	return u
+}
+
+func stores(t *testing.T) map[string]Store {
+	dir, err := NewDirStore(t.TempDir())
+	require.NoError(t, err)
+	return map[string]Store{
+		"configmap": &ConfigMapStore{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build(), Namespace: "claim-archive"},
+		"dir":       dir,
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			composite := &unstructured.Unstructured{}
+			composite.SetAPIVersion("platform.example.org/v1alpha1")
+			composite.SetKind("AWSStorage")
+			composite.SetName("bucket-x7k2p")
+			rec := NewRecord(newClaim("bucket"), composite, nil, time.Unix(1760000000, 0))
+			require.Equal(t, "alice.storage.bucket.1760000000", rec.ID)
+
+			require.NoError(t, s.Put(ctx, rec))
+			require.NoError(t, s.Put(ctx, rec), "archiving again overwrites")
+
+			got, err := s.Get(ctx, rec.ID)
+			require.NoError(t, err)
+			require.Equal(t, rec.Claim.Object, got.Claim.Object)
+			require.Equal(t, "AWSStorage", got.Composite.GetKind())
+			require.True(t, rec.ArchivedAt.Equal(got.ArchivedAt))
+
+			entries, err := s.List(ctx)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			require.Equal(t, rec.entry().ID, entries[0].ID)
+			require.Equal(t, "bucket", entries[0].Name)
+			require.True(t, rec.ArchivedAt.Equal(entries[0].ArchivedAt))
+
+			require.NoError(t, s.Delete(ctx, rec.ID))
+			_, err = s.Get(ctx, rec.ID)
+			require.ErrorIs(t, err, ErrNotFound)
+			require.ErrorIs(t, s.Delete(ctx, rec.ID), ErrNotFound)
+		})
+	}
+}
+
+func TestRetention_Prune(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			for i, age := range []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour, 48 * time.Hour} {
+				require.NoError(t, s.Put(ctx, NewRecord(newClaim(string(rune('a'+i))), nil, nil, now.Add(-age))))
+			}
+
+			require.NoError(t, Retention{MaxAge: 24 * time.Hour}.Prune(ctx, s, now))
+			entries, err := s.List(ctx)
+			require.NoError(t, err)
+			require.Len(t, entries, 3)
+
+			require.NoError(t, Retention{MaxCount: 2}.Prune(ctx, s, now))
+			entries, err = s.List(ctx)
+			require.NoError(t, err)
+			names := []string{entries[0].Name, entries[1].Name}
+			require.ElementsMatch(t, []string{"a", "b"}, names, "the newest archives are kept")
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	s, err := Open("configmap:claim-archive", nil)
+	require.NoError(t, err)
+	require.Equal(t, "claim-archive", s.(*ConfigMapStore).Namespace)
+
+	s, err = Open("dir:"+t.TempDir(), nil)
+	require.NoError(t, err)
+	require.IsType(t, &DirStore{}, s)
+
+	for _, spec := range []string{"configmap", "dir:", "s3:bucket"} {
+		_, err := Open(spec, nil)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestDirStore_RejectsPaths(t *testing.T) {
+	s, err := NewDirStore(t.TempDir())
+	require.NoError(t, err)
+	_, err = s.Get(context.Background(), "../etc/passwd")
+	require.ErrorIs(t, err, ErrNotFound)
+}
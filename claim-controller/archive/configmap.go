@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	archiveLabel     = "platform.example.org/archive"
+	kindLabel        = "platform.example.org/archived-kind"
+	namespaceLabel   = "platform.example.org/archived-namespace"
+	nameLabel        = "platform.example.org/archived-name"
+	archivedAtAnnot  = "platform.example.org/archived-at"
+	recordKey        = "record.yaml"
+	maxConfigMapSize = 1 << 20 // the size limit of a ConfigMap, metadata included
+)
+
+// ConfigMapStore keeps every archive in a ConfigMap named after its ID
+type ConfigMapStore struct {
+	Client    client.Client // not cached, the store only reads archives on demand
+	Namespace string
+}
+
+func (s *ConfigMapStore) Put(ctx context.Context, rec *Record) error {
+	b, err := rec.marshal()
+	if err != nil {
+		return err
+	}
+	if len(b) > maxConfigMapSize-16<<10 {
+		return fmt.Errorf("archive %s is %d bytes, too large for a ConfigMap", rec.ID, len(b))
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rec.ID,
+			Namespace: s.Namespace,
+			Labels: map[string]string{
+				archiveLabel:   "true",
+				kindLabel:      rec.Kind,
+				namespaceLabel: rec.Namespace,
+				nameLabel:      rec.Name,
+			},
+			Annotations: map[string]string{archivedAtAnnot: rec.ArchivedAt.Format(time.RFC3339)},
+		},
+		Data: map[string]string{recordKey: string(b)},
+	}
+	err = s.Client.Create(ctx, cm)
+	if apierrors.IsAlreadyExists(err) {
+		// archived again within the same second, i.e. the deletion failed and was retried
+		err = s.Client.Update(ctx, cm)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", rec.ID, err)
+	}
+	return nil
+}
+
+func (s *ConfigMapStore) Get(ctx context.Context, id string) (*Record, error) {
+	var cm corev1.ConfigMap
+	if err := s.Client.Get(ctx, client.ObjectKey{Namespace: s.Namespace, Name: id}, &cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+	return unmarshal([]byte(cm.Data[recordKey]))
+}
+
+func (s *ConfigMapStore) List(ctx context.Context) ([]Entry, error) {
+	var cms corev1.ConfigMapList
+	if err := s.Client.List(ctx, &cms, client.InNamespace(s.Namespace), client.MatchingLabels{archiveLabel: "true"}); err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(cms.Items))
+	for _, cm := range cms.Items {
+		archivedAt, _ := time.Parse(time.RFC3339, cm.Annotations[archivedAtAnnot])
+		entries = append(entries, Entry{
+			ID:         cm.Name,
+			Kind:       cm.Labels[kindLabel],
+			Namespace:  cm.Labels[namespaceLabel],
+			Name:       cm.Labels[nameLabel],
+			ArchivedAt: archivedAt,
+		})
+	}
+	return entries, nil
+}
+
+func (s *ConfigMapStore) Delete(ctx context.Context, id string) error {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: id, Namespace: s.Namespace}}
+	if err := s.Client.Delete(ctx, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return err
+	}
+	return nil
+}
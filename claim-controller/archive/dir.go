@@ -0,0 +1,95 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirStore keeps every archive in a YAML file named after its ID
+type DirStore struct {
+	Dir string
+}
+
+// NewDirStore creates dir if needed
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the archive directory: %w", err)
+	}
+	return &DirStore{Dir: dir}, nil
+}
+
+func (s *DirStore) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return filepath.Join(s.Dir, id+".yaml"), nil
+}
+
+func (s *DirStore) Put(_ context.Context, rec *Record) error {
+	p, err := s.path(rec.ID)
+	if err != nil {
+		return err
+	}
+	b, err := rec.marshal()
+	if err != nil {
+		return err
+	}
+	// written aside then renamed, so a crash never leaves a truncated archive
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o640); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", rec.ID, err)
+	}
+	return os.Rename(tmp, p)
+}
+
+func (s *DirStore) Get(_ context.Context, id string) (*Record, error) {
+	p, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	} else if err != nil {
+		return nil, err
+	}
+	return unmarshal(b)
+}
+
+func (s *DirStore) List(ctx context.Context) ([]Entry, error) {
+	files, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, f := range files {
+		id, ok := strings.CutSuffix(f.Name(), ".yaml")
+		if f.IsDir() || !ok {
+			continue
+		}
+		rec, err := s.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, rec.entry())
+	}
+	return entries, nil
+}
+
+func (s *DirStore) Delete(_ context.Context, id string) error {
+	p, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
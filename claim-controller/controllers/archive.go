@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"claim-controller/archive"
+)
+
+const ReasonArchiveFailed = "ArchiveFailed"
+
+// archiveClaim archives claim, its composite and the composed resources before it is deleted, then prunes the
+// archives beyond the retention
+func (r *ClaimReconciler) archiveClaim(ctx context.Context, claim *unstructured.Unstructured) (string, error) {
+	reader := r.reader()
+	composite, err := getRef(ctx, reader, claim, "spec", "resourceRef")
+	if err != nil {
+		return "", err
+	}
+	var composed []*unstructured.Unstructured
+	if composite != nil {
+		refs, _, _ := unstructured.NestedSlice(composite.Object, "spec", "resourceRefs")
+		for _, ref := range refs {
+			ref, _ := ref.(map[string]any)
+			u, err := getObject(ctx, reader, ref)
+			if err != nil {
+				return "", err
+			}
+			if u != nil {
+				composed = append(composed, u)
+			}
+		}
+	}
+
+	now := r.now()
+	rec := archive.NewRecord(claim.DeepCopy(), composite, composed, now)
+	if err := r.Archive.Put(ctx, rec); err != nil {
+		return "", err
+	}
+	ArchivedClaims.WithLabelValues().Inc()
+	if err := r.ArchiveRetention.Prune(ctx, r.Archive, now); err != nil {
+		r.Log.Error(err, "failed to prune archives") // the claim is archived all the same
+	}
+	return rec.ID, nil
+}
+
+// getRef gets the object referenced at fields of obj, nil when there is none or it is gone
+func getRef(ctx context.Context, reader client.Reader, obj *unstructured.Unstructured, fields ...string) (*unstructured.Unstructured, error) {
+	ref, ok, _ := unstructured.NestedMap(obj.Object, fields...)
+	if !ok {
+		return nil, nil
+	}
+	return getObject(ctx, reader, ref)
+}
+
+func getObject(ctx context.Context, reader client.Reader, ref map[string]any) (*unstructured.Unstructured, error) {
+	apiVersion, _ := ref["apiVersion"].(string)
+	kind, _ := ref["kind"].(string)
+	name, _ := ref["name"].(string)
+	namespace, _ := ref["namespace"].(string)
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil || kind == "" || name == "" {
+		return nil, nil
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gv.WithKind(kind))
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, u); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+	return u, nil
+}
+
+// lifecycleAnnotations are the state the controller and the api-server record on a claim over its life, dropped when
+// it is restored so that its lifetime, renewals and activity start over. TTLAnnotation is not among them: it is the
+// lifetime the user asked for, so a restored claim keeps it, including any extension made before it expired.
+var lifecycleAnnotations = []string{
+	CreationAnnotation, RenewalsAnnotation, MaxLifetimeAnnotation, LastActivityAnnotation, TraceparentAnnotation,
+	ExpiresAtAnnotation, ExpiringSoonAnnotation, ExpiryWarningAnnotation, PhaseAnnotation, HibernatedAtAnnotation,
+	RestoreAnnotation, SchedulePausedAnnotation, DeletionRefsAnnotation, DeletionStuckAnnotation,
+}
+
+// RestoreClaim recreates the claim of an archive as it was before the controller expired it: its status, its
+// reference to the deleted composite and the controller annotations are dropped, hibernation is undone, and its
+// TTL starts over, extended if the claim was. The composite and composed resources are recreated by Crossplane.
+func RestoreClaim(ctx context.Context, c client.Client, rec *archive.Record) (*unstructured.Unstructured, error) {
+	claim := rec.Claim.DeepCopy()
+	if hibernating(claim) {
+		restore, err := restorePatch(claim)
+		if err != nil {
+			return nil, err
+		}
+		mergeJSON(claim.Object, restore)
+	}
+	if schedulePaused(claim) {
+		mergeJSON(claim.Object, map[string]any{"metadata": map[string]any{"annotations": map[string]any{PausedAnnotation: nil}}})
+	}
+
+	restored := &unstructured.Unstructured{}
+	restored.SetGroupVersionKind(claim.GroupVersionKind())
+	restored.SetNamespace(claim.GetNamespace())
+	restored.SetName(claim.GetName())
+	restored.SetLabels(claim.GetLabels())
+	annotations := claim.GetAnnotations()
+	for _, a := range lifecycleAnnotations {
+		delete(annotations, a)
+	}
+	restored.SetAnnotations(annotations)
+	if spec, ok := claim.Object["spec"].(map[string]any); ok {
+		delete(spec, "resourceRef")
+		restored.Object["spec"] = spec
+	}
+	if err := c.Create(ctx, restored); err != nil {
+		return nil, fmt.Errorf("failed to restore %s %s/%s: %w", rec.Kind, rec.Namespace, rec.Name, err)
+	}
+	return restored, nil
+}
+
+// mergeJSON applies a JSON merge patch to obj in place
+func mergeJSON(obj, patch map[string]any) map[string]any {
+	for k, v := range patch {
+		switch v := v.(type) {
+		case nil:
+			delete(obj, k)
+		case map[string]any:
+			current, _ := obj[k].(map[string]any)
+			if current == nil {
+				current = map[string]any{}
+			}
+			obj[k] = mergeJSON(current, v)
+		default:
+			obj[k] = v
+		}
+	}
+	return obj
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"claim-controller/archive"
+)
+
+var (
+	compositeGVK = schema.GroupVersionKind{Group: APIGroup, Version: APIVersion, Kind: "AWSStorage"}
+	bucketGVK    = schema.GroupVersionKind{Group: "s3.aws.upbound.io", Version: "v1beta1", Kind: "Bucket"}
+)
+
+func newTestObject(gvk schema.GroupVersionKind, name string, spec map[string]any) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	u.SetGroupVersionKind(gvk)
+	u.SetName(name)
+	return u
+}
+
+// failingStore fails every archive
+type failingStore struct{ archive.Store }
+
+func (failingStore) Put(context.Context, *archive.Record) error {
+	return errors.New("archive unavailable")
+}
+
+func TestClaimReconciler_ArchiveBeforeDelete(t *testing.T) {
+	scheme := newTestScheme()
+	scheme.AddKnownTypeWithName(compositeGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(bucketGVK, &unstructured.Unstructured{})
+
+	claim := newTestClaim("bucket", map[string]string{CreationAnnotation: time.Now().Add(-time.Hour).Local().Format(time.RFC3339)})
+	claim.Spec = map[string]any{
+		"location":    "EU",
+		"resourceRef": map[string]any{"apiVersion": compositeGVK.GroupVersion().String(), "kind": "AWSStorage", "name": "bucket-x7k2p"},
+	}
+	composite := newTestObject(compositeGVK, "bucket-x7k2p", map[string]any{"resourceRefs": []any{
+		map[string]any{"apiVersion": bucketGVK.GroupVersion().String(), "kind": "Bucket", "name": "bucket-x7k2p-s3"},
+		map[string]any{"apiVersion": bucketGVK.GroupVersion().String(), "kind": "Bucket", "name": "already-gone"},
+	}})
+	bucket := newTestObject(bucketGVK, "bucket-x7k2p-s3", map[string]any{"forProvider": map[string]any{"region": "eu-west-1"}})
+
+	tests := []struct {
+		name          string
+		store         func(t *testing.T) archive.Store
+		expectErr     bool
+		expectDeleted bool
+	}{
+		{
+			name: "archives the claim, its composite and composed resources",
+			store: func(t *testing.T) archive.Store {
+				s, err := archive.NewDirStore(t.TempDir())
+				require.NoError(t, err)
+				return s
+			},
+			expectDeleted: true,
+		},
+		{
+			name:      "keeps the claim when it cannot be archived",
+			store:     func(*testing.T) archive.Store { return failingStore{} },
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim.DeepCopyObject().(client.Object), composite.DeepCopy(), bucket.DeepCopy()).Build()
+			r, recorder := newHibernateReconciler(c)
+			r.Archive = tt.store(t)
+			r.GracePeriod = 0
+			ArchivedClaims.Reset()
+
+			key := types.NamespacedName{Name: "bucket", Namespace: "default"}
+			_, err := r.Reconcile(context.Background(), ClaimRequest{GVK: fakeGVK, NamespacedName: key})
+			if !tt.expectDeleted {
+				require.ErrorContains(t, err, "archive unavailable")
+				require.Contains(t, <-recorder.Events, "Warning ArchiveFailed ")
+				require.Equal(t, float64(0), testutil.ToFloat64(ArchivedClaims.WithLabelValues()))
+				require.Nil(t, getClaim(t, c, "bucket").DeletionTimestamp)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, getClaim(t, c, "bucket").DeletionTimestamp, "deleted, waiting for its composite")
+			require.Equal(t, float64(1), testutil.ToFloat64(ArchivedClaims.WithLabelValues()))
+
+			entries, err := r.Archive.List(context.Background())
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			rec, err := r.Archive.Get(context.Background(), entries[0].ID)
+			require.NoError(t, err)
+			require.Equal(t, "bucket", rec.Claim.GetName())
+			require.Equal(t, "bucket-x7k2p", rec.Composite.GetName())
+			require.Len(t, rec.Composed, 1, "resources already gone are skipped")
+			require.Equal(t, "eu-west-1", rec.Composed[0].Object["spec"].(map[string]any)["forProvider"].(map[string]any)["region"])
+		})
+	}
+}
+
+func TestRestoreClaim(t *testing.T) {
+	claim := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"location":    "EU",
+			"size":        "small",
+			"resourceRef": map[string]any{"apiVersion": compositeGVK.GroupVersion().String(), "kind": "AWSStorage", "name": "bucket-x7k2p"},
+		},
+		"status": map[string]any{"conditions": []any{}},
+	}}
+	claim.SetGroupVersionKind(fakeGVK)
+	claim.SetNamespace("default")
+	claim.SetName("bucket")
+	claim.SetUID("0b9f0a54")
+	claim.SetResourceVersion("42")
+	claim.SetLabels(map[string]string{"platform.example.org/team": "data"})
+	claim.SetAnnotations(map[string]string{
+		CreationAnnotation:     "2026-10-01T10:00:00Z",
+		TTLAnnotation:          "4h",
+		RenewalsAnnotation:     "3",
+		MaxLifetimeAnnotation:  "30m",
+		LastActivityAnnotation: "2026-10-01T10:20:00Z",
+		TraceparentAnnotation:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		PhaseAnnotation:        PhaseHibernating,
+		HibernatedAtAnnotation: "2026-10-01T10:30:00Z",
+		PausedAnnotation:       "true",
+		RestoreAnnotation:      `{"metadata": {"annotations": {"crossplane.io/paused": null}}, "spec": {"size": "large"}}`,
+		ExpiresAtAnnotation:    "2026-10-01T11:30:00Z",
+		ExpiringSoonAnnotation: "2026-10-01T11:30:00Z",
+		DeletionRefsAnnotation: `[]`,
+	})
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).Build()
+
+	_, err := RestoreClaim(context.Background(), c, archive.NewRecord(claim, nil, nil, time.Now()))
+	require.NoError(t, err)
+
+	var restored FakeClaim
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "bucket", Namespace: "default"}, &restored))
+	require.Equal(t, map[string]string{TTLAnnotation: "4h"}, restored.Annotations, "hibernation is undone and the TTL, extended 3 times, starts over")
+	require.Equal(t, map[string]string{"platform.example.org/team": "data"}, restored.Labels)
+	require.Equal(t, map[string]any{"location": "EU", "size": "large"}, restored.Spec)
+	require.NotEqual(t, "0b9f0a54", string(restored.UID))
+
+	// the full TTL runs from the restore, renewals and idle time are not carried over
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	r, _ := newHibernateReconciler(c)
+	r.Clock = clocktesting.NewFakePassiveClock(now)
+	r.IdleTimeout = 8 * time.Hour
+	reconcileClaim(t, r, "bucket")
+	require.Equal(t, now.Local().Format(time.RFC3339), getClaim(t, c, "bucket").Annotations[CreationAnnotation])
+	require.Equal(t, 4*time.Hour, reconcileClaim(t, r, "bucket"))
+}
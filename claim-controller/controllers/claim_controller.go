@@ -3,16 +3,28 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"claim-controller/api/v1alpha1"
+	"claim-controller/archive"
 )
 
 const (
@@ -20,56 +32,110 @@ const (
 	APIVersion         = "v1alpha1"
 	TTLSeconds         = 600 // 10 minutes
 	CreationAnnotation = "platform.example.org/creationTimestamp"
+	// TTLAnnotation is the lifetime requested at submission as a Go duration (i.e. 4h), set by the api-server
+	TTLAnnotation = "platform.example.org/ttl"
+	// MaxLifetimeAnnotation caps TTLAnnotation, recorded by the api-server when a claim is extended
+	MaxLifetimeAnnotation = "platform.example.org/max-lifetime"
+	// RenewalsAnnotation counts the extensions of a claim, recorded by the api-server
+	RenewalsAnnotation = "platform.example.org/renewals"
 )
 
-var claims = []string{"Storage", "Compute"}
+// Reasons of the Events recorded on claims
+const (
+	ReasonInvalidTTL               = "InvalidTTL"
+	ReasonInvalidMaxLifetime       = "InvalidMaxLifetime"
+	ReasonInvalidCreationTimestamp = "InvalidCreationTimestamp"
+	ReasonTTLClamped               = "TTLClamped"
+)
+
+// ClaimRequest identifies the claim to reconcile by kind as well as name, claims of different kinds in a
+// namespace may share a name
+type ClaimRequest struct {
+	GVK schema.GroupVersionKind
+	types.NamespacedName
+}
+
+func (r ClaimRequest) String() string {
+	return r.GVK.Kind + " " + r.NamespacedName.String()
+}
 
 type ClaimReconciler struct {
 	client.Client
-	Log        logr.Logger
+	Log logr.Logger
+	// Kinds are the claim kinds to expire, kept up to date by the XRDReconciler
+	Kinds *ClaimKinds
+	// TTLSeconds is the lifetime of claims without a TTLAnnotation whose kind is not in KindTTLs
 	TTLSeconds int64
+	// KindTTLs are per-kind defaults overriding TTLSeconds, keyed by kind (i.e. Compute). A matching
+	// ClaimLifecyclePolicy overrides both.
+	KindTTLs map[string]time.Duration
+	// MaxTTL clamps every lifetime, requested or default, zero meaning no limit. It also bounds policies.
+	MaxTTL time.Duration
+	// Recorder reports annotations the reconciler could not honour as Events on the claim, may be nil
+	Recorder record.EventRecorder
+	// GracePeriod is how long expired claims hibernate before they are deleted, zero deleting them on expiry.
+	// A matching ClaimLifecyclePolicy overrides it.
+	GracePeriod time.Duration
+	// HibernatePatches are JSON merge patches scaling down hibernated claims, keyed by kind; claims of other
+	// kinds are paused
+	HibernatePatches map[string]map[string]any
+	// Archive keeps the manifests of claims before they are deleted, nil deleting them without archiving
+	Archive          archive.Store
+	ArchiveRetention archive.Retention
+	// APIReader reads composites and composed resources to archive without caching them, Client when nil
+	APIReader client.Reader
+	// DeletionTimeout is how long deleted claims may wait for their composite and composed resources before they
+	// are flagged as stuck, zero never flagging them
+	DeletionTimeout time.Duration
+	deleting        deleting
+	// Clock tells the time of expiries and schedules, the real clock when nil
+	Clock clock.PassiveClock
+	// WarnBefore are the lead times of the warnings issued before claims are deleted, longest first
+	WarnBefore []time.Duration
+	// IdleTimeout expires claims without activity in LastActivityAnnotation for this long before their TTL, zero
+	// expiring them on their TTL only. A matching ClaimLifecyclePolicy overrides it.
+	IdleTimeout time.Duration
 }
 
-func (r *ClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *ClaimReconciler) Reconcile(ctx context.Context, req ClaimRequest) (ctrl.Result, error) {
 	start := time.Now()
 	// defer because it's unknown a prior where exactly this method will return
 	defer func() {
 		ReconcileDuration.Observe(time.Since(start).Seconds())
 	}()
 
-	log := r.Log.WithValues("Claim", req.NamespacedName)
+	log := r.Log.WithValues("Claim", req.NamespacedName, "kind", req.GVK.Kind)
+	key := req.GVK.Kind + "/" + req.Namespace + "/" + req.Name
 
+	claimSchema := req.GVK
 	claim := &unstructured.Unstructured{}
-	var err error
-	var claimSchema schema.GroupVersionKind
-
-	for _, c := range claims {
-		claimSchema = schema.GroupVersionKind{
-			Group:   APIGroup,
-			Version: APIVersion,
-			Kind:    c,
-		}
-		claim.SetGroupVersionKind(claimSchema)
-
-		err = r.Get(ctx, req.NamespacedName, claim)
-
-		if err == nil {
-			if !claim.GetDeletionTimestamp().IsZero() {
-				// Already being deleted, skip processing
-				return ctrl.Result{}, nil
-			}
-			break
-		}
-	}
-
-	if err != nil {
+	claim.SetGroupVersionKind(claimSchema)
+	if err := r.Get(ctx, req.NamespacedName, claim); err != nil {
 		if client.IgnoreNotFound(err) == nil {
 			log.Info("Claim not found")
+			r.deleting.setStuck(key, false)
+			ClaimLifetimes.forget(key)
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "failed to get Claim")
 		return ctrl.Result{}, err
 	}
+	if !claim.GetDeletionTimestamp().IsZero() {
+		// Already being deleted, follow it through if the controller deleted it
+		ClaimLifetimes.forget(key)
+		return r.reconcileDeletion(ctx, claim, log)
+	}
+
+	// Continue the trace started by the api-server request that created the claim
+	ctx, span := tracer.Start(contextFromClaim(ctx, claim), "ClaimReconciler.Reconcile", trace.WithAttributes(
+		attribute.String("claim.kind", claim.GetKind()),
+		attribute.String("claim.namespace", claim.GetNamespace()),
+		attribute.String("claim.name", claim.GetName()),
+	))
+	defer span.End()
+	if id := traceID(ctx); id != "" {
+		log = log.WithValues("trace_id", id)
+	}
 
 	creationTimeStr, exists := claim.GetAnnotations()[CreationAnnotation] // will not panic even if annotations is nil
 
@@ -87,7 +153,7 @@ func (r *ClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			if annotations == nil {
 				annotations = map[string]string{}
 			}
-			annotations[CreationAnnotation] = time.Now().Local().Format(time.RFC3339)
+			annotations[CreationAnnotation] = r.now().Local().Format(time.RFC3339)
 			latest.SetAnnotations(annotations)
 
 			// the Update will trigger a new event
@@ -95,6 +161,8 @@ func (r *ClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		})
 
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 			log.Error(err, "failed to add creation annotation with retry")
 			return ctrl.Result{}, fmt.Errorf("failed to add creation annotation: %w", err)
 		}
@@ -105,64 +173,254 @@ func (r *ClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, nil
 	}
 
+	policy, err := r.policy(ctx, claim)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(err, "failed to resolve the lifecycle policy")
+		return ctrl.Result{}, err
+	}
+	if policy != nil {
+		log = log.WithValues("policy", policy.Name)
+		span.SetAttributes(attribute.String("claim.policy", policy.Name))
+	}
+	if exempted, err := exempt(policy, claim); err != nil {
+		log.Error(err, "ignoring the exemptions of the lifecycle policy")
+	} else if exempted {
+		// re-evaluated when the policy changes
+		log.Info("exempted from expiry")
+		ClaimLifetimes.forget(key)
+		if err := r.clearExpiry(ctx, claim); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error(err, "failed to clear the expiry of exempted Claim")
+			return ctrl.Result{}, err
+		}
+		SkippedClaims.WithLabelValues().Inc()
+		return ctrl.Result{}, nil
+	}
+
 	creationTime, err := time.Parse(time.RFC3339, creationTimeStr)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("invalid creation timestamp: %w", err)
+		// an edited annotation would otherwise fail every reconcile and keep the claim forever
+		r.event(claim, corev1.EventTypeWarning, ReasonInvalidCreationTimestamp,
+			"Ignoring %s %q, the TTL counts from the creation of the claim", CreationAnnotation, creationTimeStr)
+		log.Info("ignoring invalid creation annotation", "creationTimestamp", creationTimeStr)
+		creationTime = claim.GetCreationTimestamp().Time
+	}
+	now := r.now()
+	age := now.Sub(creationTime)
+	ttl := r.ttl(claim, policy, log)
+	grace := r.gracePeriod(policy)
+	idle := r.idleTimeout(policy)
+	lastActivity := r.lastActivity(claim, creationTime)
+	ClaimLifetimes.observe(claim, ttl, lastActivity)
+
+	// idle claims expire before their TTL, activity postponing it
+	cause := "TTL elapsed"
+	if idleTTL := lastActivity.Add(idle).Sub(creationTime); idle > 0 && idleTTL < ttl {
+		ttl = idleTTL
+		cause = fmt.Sprintf("Idle for %s", idle)
+		log = log.WithValues("idleTimeout", idle.String(), "lastActivity", lastActivity.Format(time.RFC3339))
 	}
-	age := time.Since(creationTime)
 
 	// Check if claim is older than max age
-	if age.Seconds() >= float64(r.TTLSeconds) {
-		log.Info("deleting expired", "Claim", req.NamespacedName, "age", age.String())
-
+	if age >= ttl+grace {
+		log.Info("deleting expired", "Claim", req.NamespacedName, "age", age.String(), "ttl", ttl.String(), "cause", cause)
+
+		if r.Archive != nil {
+			id, err := r.archiveClaim(ctx, claim)
+			if err != nil {
+				// the claim is kept until it can be archived
+				r.event(claim, corev1.EventTypeWarning, ReasonArchiveFailed, "Failed to archive the claim before deleting it: %v", err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				log.Error(err, "failed to archive expired Claim")
+				return ctrl.Result{}, err
+			}
+			log = log.WithValues("archive", id)
+		}
+		if hibernating(claim) {
+			if err := r.unpause(ctx, claim); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				log.Error(err, "failed to unpause hibernated Claim")
+				return ctrl.Result{}, err
+			}
+		}
+		if schedulePaused(claim) {
+			if err := r.unpauseSchedule(ctx, claim); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				log.Error(err, "failed to unpause Claim paused by its schedule")
+				return ctrl.Result{}, err
+			}
+		}
+		if err := r.trackDeletion(ctx, claim); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error(err, "failed to track the deletion of expired Claim")
+			return ctrl.Result{}, err
+		}
 		// Delete creates a new event (safely idempotent)
 		if err := r.Delete(ctx, claim); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 			log.Error(err, "failed to delete expired Claim", "Claim", req.NamespacedName)
 			return ctrl.Result{}, err
 		}
 
 		DeletedClaims.WithLabelValues().Inc()
+		ClaimLifetimes.forget(key)
 
-		return ctrl.Result{}, nil
+		// claims without composed resources left are released at once, the others are followed through
+		if err := r.Get(ctx, req.NamespacedName, claim); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if claim.GetDeletionTimestamp().IsZero() {
+			// the cache has yet to see the deletion, whose event brings the claim back
+			return ctrl.Result{}, nil
+		}
+		return r.reconcileDeletion(ctx, claim, log)
 	}
 
-	SkippedClaims.WithLabelValues().Inc()
+	// expired claims hibernate for their grace period, extending the TTL or using them revives them
+	var phaseErr error
+	switch {
+	case age >= ttl && !hibernating(claim):
+		log.Info("hibernating expired", "age", age.String(), "ttl", ttl.String(), "gracePeriod", grace.String())
+		phaseErr = r.hibernate(ctx, claim, cause, creationTime.Add(ttl+grace))
+	case age < ttl && hibernating(claim):
+		log.Info("reviving extended", "age", age.String(), "ttl", ttl.String())
+		phaseErr = r.revive(ctx, claim, revivedBy(claim, lastActivity))
+	}
+	if phaseErr != nil {
+		span.RecordError(phaseErr)
+		span.SetStatus(codes.Error, phaseErr.Error())
+		log.Error(phaseErr, "failed to change the lifecycle phase")
+		return ctrl.Result{}, phaseErr
+	}
 
-	remaining := time.Duration(r.TTLSeconds)*time.Second - age
+	remaining := ttl + grace - age
+	if age < ttl {
+		remaining = ttl - age // hibernated on expiry
+
+		// hibernated claims stay paused whatever their schedule
+		next, err := r.applySchedule(ctx, claim, policy, now)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error(err, "failed to apply the schedule")
+			return ctrl.Result{}, err
+		}
+		if next > 0 {
+			// wake up for the next pause or resume
+			remaining = min(remaining, next)
+		}
+	}
+	if next, err := r.warnExpiry(ctx, claim, ttl+grace, creationTime.Add(ttl+grace), now); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(err, "failed to warn of the expiry")
+		return ctrl.Result{}, err
+	} else if next > 0 {
+		// wake up for the next warning, which comes before the deletion
+		remaining = min(remaining, next)
+	}
+
+	SkippedClaims.WithLabelValues().Inc()
 
 	log.Info("reconciled", "age", creationTimeStr, "requeueing after", remaining)
 
 	return ctrl.Result{RequeueAfter: remaining}, nil
 }
 
-func (r *ClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	storageObj := &unstructured.Unstructured{}
-	storageObj.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   APIGroup,
-		Version: APIVersion,
-		Kind:    "Storage",
-	})
-
-	computeObj := &unstructured.Unstructured{}
-	computeObj.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   APIGroup,
-		Version: APIVersion,
-		Kind:    "Compute",
-	})
-
-	return ctrl.NewControllerManagedBy(mgr).
-		For(storageObj).
-		Watches(computeObj, &handler.EnqueueRequestForObject{}).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 2}).
-		Complete(r)
+// ttl is the lifetime requested in TTLAnnotation, or the default of the policy or claim kind when it is missing
+// or invalid, capped by MaxLifetimeAnnotation, the policy and MaxTTL. Annotations that cannot be honoured are
+// reported as Events. policy may be nil.
+func (r *ClaimReconciler) ttl(claim client.Object, policy *v1alpha1.ClaimLifecyclePolicy, log logr.Logger) time.Duration {
+	ttl := r.defaultTTL(claim.GetObjectKind().GroupVersionKind().Kind)
+	if policy != nil && policy.Spec.DefaultTTL != nil && policy.Spec.DefaultTTL.Duration > 0 {
+		ttl = policy.Spec.DefaultTTL.Duration
+	}
+	annotations := claim.GetAnnotations()
+	if v, ok := annotations[TTLAnnotation]; ok {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			ttl = d
+		} else {
+			r.event(claim, corev1.EventTypeWarning, ReasonInvalidTTL,
+				"Ignoring %s %q, expected a positive duration such as 4h; using the default of %s", TTLAnnotation, v, ttl)
+			log.Info("ignoring invalid ttl annotation", "ttl", v)
+		}
+	}
+	if v, ok := annotations[MaxLifetimeAnnotation]; ok {
+		if limit, err := time.ParseDuration(v); err == nil && limit > 0 {
+			ttl = min(ttl, limit)
+		} else {
+			r.event(claim, corev1.EventTypeWarning, ReasonInvalidMaxLifetime,
+				"Ignoring %s %q, expected a positive duration such as 8h", MaxLifetimeAnnotation, v)
+			log.Info("ignoring invalid max-lifetime annotation", "maxLifetime", v)
+		}
+	}
+	if policy != nil && policy.Spec.MaxTTL != nil && policy.Spec.MaxTTL.Duration > 0 && ttl > policy.Spec.MaxTTL.Duration {
+		r.event(claim, corev1.EventTypeNormal, ReasonTTLClamped,
+			"TTL of %s exceeds the maximum of %s set by lifecycle policy %s, the claim expires after %s", ttl, policy.Spec.MaxTTL.Duration, policy.Name, policy.Spec.MaxTTL.Duration)
+		ttl = policy.Spec.MaxTTL.Duration
+	}
+	if r.MaxTTL > 0 && ttl > r.MaxTTL {
+		r.event(claim, corev1.EventTypeNormal, ReasonTTLClamped,
+			"TTL of %s exceeds the maximum of %s allowed by the platform, the claim expires after %s", ttl, r.MaxTTL, r.MaxTTL)
+		ttl = r.MaxTTL
+	}
+	return ttl
+}
+
+func (r *ClaimReconciler) defaultTTL(kind string) time.Duration {
+	if d, ok := r.KindTTLs[kind]; ok {
+		return d
+	}
+	return time.Duration(r.TTLSeconds) * time.Second
 }
 
-/*
-For(base, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
-	gvk := obj.GetObjectKind().GroupVersionKind()
-	return (gvk.Group == APIGroup && gvk.Version == APIVersion &&
-		(gvk.Kind == "Storage" || gvk.Kind == "Compute"))
-})))
+func (r *ClaimReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
+}
 
-Watches(computeObj, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &Storage{}))
-*/
+func (r *ClaimReconciler) event(claim client.Object, eventType, reason, format string, args ...any) {
+	if r.Recorder != nil {
+		r.Recorder.Eventf(claim, eventType, reason, format, args...)
+	}
+}
+
+// ParseKindTTLs parses per-kind default TTLs written as Kind=duration pairs, i.e. Storage=4h,Compute=1h
+func ParseKindTTLs(s string) (map[string]time.Duration, error) {
+	ttls := map[string]time.Duration{}
+	if s == "" {
+		return ttls, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kind, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || kind == "" {
+			return nil, fmt.Errorf("invalid kind ttl %q, expected Kind=duration", pair)
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid ttl %q for %s, expected a positive duration", v, kind)
+		}
+		ttls[kind] = d
+	}
+	return ttls, nil
+}
+
+// SetupWithManager registers the claim controller, watching lifecycle policies only: the XRDReconciler adds
+// the watches of claim kinds through the returned controller as XRDs offer them
+func (r *ClaimReconciler) SetupWithManager(mgr ctrl.Manager) (controller.TypedController[ClaimRequest], error) {
+	return builder.TypedControllerManagedBy[ClaimRequest](mgr).
+		Named("claim").
+		Watches(&v1alpha1.ClaimLifecyclePolicy{}, handler.TypedEnqueueRequestsFromMapFunc(r.claimsOf)).
+		WithOptions(controller.TypedOptions[ClaimRequest]{MaxConcurrentReconciles: 2}).
+		Build(r)
+}
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,16 +15,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"claim-controller/api/v1alpha1"
 )
 
 type FakeClaim struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
-	Spec              map[string]string `json:"spec,omitempty"`
+	Spec              map[string]any `json:"spec,omitempty"`
 }
 
 func (f *FakeClaim) DeepCopyObject() runtime.Object {
@@ -30,12 +37,63 @@ func (f *FakeClaim) DeepCopyObject() runtime.Object {
 	return &copy
 }
 
+type FakeClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FakeClaim `json:"items"`
+}
+
+func (f *FakeClaimList) DeepCopyObject() runtime.Object {
+	copy := *f
+	copy.Items = append([]FakeClaim(nil), f.Items...)
+	return &copy
+}
+
+// FakeComputeClaim is a Compute claim, the fake client keys objects by their Go type
+type FakeComputeClaim FakeClaim
+
+func (f *FakeComputeClaim) DeepCopyObject() runtime.Object {
+	copy := *f
+	return &copy
+}
+
+type FakeComputeClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FakeComputeClaim `json:"items"`
+}
+
+func (f *FakeComputeClaimList) DeepCopyObject() runtime.Object {
+	copy := *f
+	copy.Items = append([]FakeComputeClaim(nil), f.Items...)
+	return &copy
+}
+
 var fakeGVK = schema.GroupVersionKind{
 	Group:   APIGroup,
 	Version: APIVersion,
 	Kind:    "Storage",
 }
 
+var computeGVK = fakeGVK.GroupVersion().WithKind("Compute")
+
+func newTestComputeClaim(name string, annotations map[string]string) *FakeComputeClaim {
+	claim := FakeComputeClaim(*newTestClaim(name, annotations))
+	claim.Kind = "Compute"
+	return &claim
+}
+
+// newTestScheme knows FakeClaim as a Storage and a Compute claim and the lifecycle policies
+func newTestScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(fakeGVK, &FakeClaim{})
+	scheme.AddKnownTypeWithName(fakeGVK.GroupVersion().WithKind("StorageList"), &FakeClaimList{})
+	scheme.AddKnownTypeWithName(computeGVK, &FakeComputeClaim{})
+	scheme.AddKnownTypeWithName(computeGVK.GroupVersion().WithKind("ComputeList"), &FakeComputeClaimList{})
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
 func newTestClaim(name string, annotations map[string]string) *FakeClaim {
 	return &FakeClaim{
 		TypeMeta: metav1.TypeMeta{
@@ -74,6 +132,30 @@ func TestClaimReconciler_Reconcile(t *testing.T) {
 			ttl:           TTLSeconds,
 			expectSkipped: true,
 		},
+		{
+			name:          "honours a longer requested ttl",
+			claims:        []client.Object{newTestClaim("extended", map[string]string{CreationAnnotation: expired, TTLAnnotation: "2h"})},
+			ttl:           TTLSeconds,
+			expectSkipped: true,
+		},
+		{
+			name:          "honours a shorter requested ttl",
+			claims:        []client.Object{newTestClaim("short", map[string]string{CreationAnnotation: expired, TTLAnnotation: "30m"})},
+			ttl:           7200,
+			expectDeleted: true,
+		},
+		{
+			name:          "falls back to the default ttl when the annotation is invalid",
+			claims:        []client.Object{newTestClaim("invalid", map[string]string{CreationAnnotation: expired, TTLAnnotation: "forever"})},
+			ttl:           TTLSeconds,
+			expectDeleted: true,
+		},
+		{
+			name:          "caps an extended ttl at the max lifetime",
+			claims:        []client.Object{newTestClaim("capped", map[string]string{CreationAnnotation: expired, TTLAnnotation: "2h", MaxLifetimeAnnotation: "30m"})},
+			ttl:           TTLSeconds,
+			expectDeleted: true,
+		},
 		{
 			name:          "updates new claim",
 			claims:        []client.Object{newTestClaim("new", nil)},
@@ -84,8 +166,7 @@ func TestClaimReconciler_Reconcile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			scheme := runtime.NewScheme()
-			scheme.AddKnownTypeWithName(fakeGVK, &FakeClaim{})
+			scheme := newTestScheme()
 			fakeClient := fake.
 				NewClientBuilder().
 				WithScheme(scheme).
@@ -107,21 +188,24 @@ func TestClaimReconciler_Reconcile(t *testing.T) {
 			// Build reconciler
 			r := &ClaimReconciler{
 				Client:     fakeClient,
+				Kinds:      NewClaimKinds(fakeGVK),
 				Log:        zap.New(zap.UseDevMode(true)),
 				TTLSeconds: tt.ttl,
 			}
 
 			// Build request
-			var req reconcile.Request
+			req := ClaimRequest{GVK: fakeGVK}
 			if len(tt.claims) > 0 {
-				req = reconcile.Request{
+				req = ClaimRequest{
+					GVK: fakeGVK,
 					NamespacedName: types.NamespacedName{
 						Name:      tt.claims[0].GetName(),
 						Namespace: tt.claims[0].GetNamespace(),
 					},
 				}
 			} else {
-				req = reconcile.Request{
+				req = ClaimRequest{
+					GVK: fakeGVK,
 					NamespacedName: types.NamespacedName{
 						Name:      "missing",
 						Namespace: "default",
@@ -155,3 +239,203 @@ func TestClaimReconciler_Reconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestClaimReconciler_TTL(t *testing.T) {
+	tests := []struct {
+		name        string
+		kind        string
+		annotations map[string]string
+		expectTTL   time.Duration
+		expectEvent string
+	}{
+		{
+			name:      "falls back to the default ttl",
+			kind:      "Storage",
+			expectTTL: TTLSeconds * time.Second,
+		},
+		{
+			name:      "falls back to the default of the kind",
+			kind:      "Compute",
+			expectTTL: time.Hour,
+		},
+		{
+			name:        "honours the requested ttl",
+			kind:        "Compute",
+			annotations: map[string]string{TTLAnnotation: "30m"},
+			expectTTL:   30 * time.Minute,
+		},
+		{
+			name:        "reports an invalid ttl",
+			kind:        "Compute",
+			annotations: map[string]string{TTLAnnotation: "forever"},
+			expectTTL:   time.Hour,
+			expectEvent: "Warning InvalidTTL",
+		},
+		{
+			name:        "reports a negative ttl",
+			kind:        "Storage",
+			annotations: map[string]string{TTLAnnotation: "-1h"},
+			expectTTL:   TTLSeconds * time.Second,
+			expectEvent: "Warning InvalidTTL",
+		},
+		{
+			name:        "reports an invalid max lifetime",
+			kind:        "Storage",
+			annotations: map[string]string{TTLAnnotation: "2h", MaxLifetimeAnnotation: "never"},
+			expectTTL:   2 * time.Hour,
+			expectEvent: "Warning InvalidMaxLifetime",
+		},
+		{
+			name:        "clamps to the admin maximum",
+			kind:        "Storage",
+			annotations: map[string]string{TTLAnnotation: "72h"},
+			expectTTL:   24 * time.Hour,
+			expectEvent: "Normal TTLClamped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			r := &ClaimReconciler{
+				Log:        zap.New(zap.UseDevMode(true)),
+				TTLSeconds: TTLSeconds,
+				KindTTLs:   map[string]time.Duration{"Compute": time.Hour},
+				MaxTTL:     24 * time.Hour,
+				Recorder:   recorder,
+			}
+			claim := newTestClaim("claim", tt.annotations)
+			claim.Kind = tt.kind
+
+			require.Equal(t, tt.expectTTL, r.ttl(claim, nil, r.Log))
+			if tt.expectEvent == "" {
+				require.Empty(t, recorder.Events)
+				return
+			}
+			require.Len(t, recorder.Events, 1)
+			require.True(t, strings.HasPrefix(<-recorder.Events, tt.expectEvent+" "))
+		})
+	}
+}
+
+func TestClaimReconciler_InvalidCreationTimestamp(t *testing.T) {
+	scheme := newTestScheme()
+	claim := newTestClaim("edited", map[string]string{CreationAnnotation: "yesterday"})
+	claim.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	recorder := record.NewFakeRecorder(10)
+	r := &ClaimReconciler{
+		Client:     fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).Build(),
+		Kinds:      NewClaimKinds(fakeGVK),
+		Log:        zap.New(zap.UseDevMode(true)),
+		TTLSeconds: TTLSeconds,
+		Recorder:   recorder,
+	}
+	DeletedClaims.Reset()
+
+	_, err := r.Reconcile(context.Background(), ClaimRequest{GVK: fakeGVK, NamespacedName: types.NamespacedName{Name: "edited", Namespace: "default"}})
+	require.NoError(t, err)
+	require.Equal(t, float64(1), testutil.ToFloat64(DeletedClaims.WithLabelValues()), "the ttl counts from the creation of the claim")
+	require.True(t, strings.HasPrefix(<-recorder.Events, "Warning InvalidCreationTimestamp "))
+}
+
+func TestClaimReconciler_StampsCreation(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(newTestClaim("new", nil)).Build()
+	r, _ := newHibernateReconciler(c)
+	r.Clock = clocktesting.NewFakePassiveClock(now)
+
+	reconcileClaim(t, r, "new")
+	require.Equal(t, now.Local().Format(time.RFC3339), getClaim(t, c, "new").Annotations[CreationAnnotation])
+}
+
+func TestParseKindTTLs(t *testing.T) {
+	ttls, err := ParseKindTTLs("Storage=4h, Compute=90m")
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Duration{"Storage": 4 * time.Hour, "Compute": 90 * time.Minute}, ttls)
+
+	ttls, err = ParseKindTTLs("")
+	require.NoError(t, err)
+	require.Empty(t, ttls)
+
+	for _, s := range []string{"Storage", "=4h", "Storage=forever", "Compute=0s"} {
+		_, err := ParseKindTTLs(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestClaimReconciler_SameNameKinds(t *testing.T) {
+	now := time.Now().Local().Format(time.RFC3339)
+	expired := time.Now().Local().Add(-1 * time.Hour).Format(time.RFC3339)
+	storage := newTestClaim("shared", map[string]string{CreationAnnotation: now})
+	compute := newTestComputeClaim("shared", map[string]string{CreationAnnotation: expired})
+	key := types.NamespacedName{Name: "shared", Namespace: "default"}
+
+	failCompute := interceptor.Funcs{Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+		if obj.GetObjectKind().GroupVersionKind().Kind == "Compute" {
+			return errors.New("connection refused")
+		}
+		return c.Get(ctx, key, obj, opts...)
+	}}
+
+	tests := []struct {
+		name          string
+		kind          schema.GroupVersionKind
+		interceptor   interceptor.Funcs
+		expectErr     bool
+		expectDeleted bool
+		expectSkipped bool
+	}{
+		{
+			name:          "expires the claim of the requested kind",
+			kind:          computeGVK,
+			expectDeleted: true,
+		},
+		{
+			name:          "keeps the claim of another kind sharing its name",
+			kind:          fakeGVK,
+			expectSkipped: true,
+		},
+		{
+			name:        "reports the error of the requested kind",
+			kind:        computeGVK,
+			interceptor: failCompute,
+			expectErr:   true,
+		},
+		{
+			name:          "is not affected by the errors of other kinds",
+			kind:          fakeGVK,
+			interceptor:   failCompute,
+			expectSkipped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().
+				WithScheme(newTestScheme()).
+				WithObjects(storage.DeepCopyObject().(client.Object), compute.DeepCopyObject().(client.Object)).
+				WithInterceptorFuncs(tt.interceptor).
+				Build()
+			r := &ClaimReconciler{
+				Client:     c,
+				Log:        zap.New(zap.UseDevMode(true)),
+				Kinds:      NewClaimKinds(fakeGVK, computeGVK),
+				TTLSeconds: TTLSeconds,
+			}
+			DeletedClaims.Reset()
+			SkippedClaims.Reset()
+
+			_, err := r.Reconcile(context.Background(), ClaimRequest{GVK: tt.kind, NamespacedName: key})
+			if tt.expectErr {
+				require.ErrorContains(t, err, "connection refused")
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expectDeleted, testutil.ToFloat64(DeletedClaims.WithLabelValues()) == 1)
+			require.Equal(t, tt.expectSkipped, testutil.ToFloat64(SkippedClaims.WithLabelValues()) == 1)
+			if tt.expectDeleted {
+				require.NoError(t, c.Get(context.Background(), key, &FakeClaim{TypeMeta: storage.TypeMeta}), "the Storage claim is left alone")
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// DeletionFinalizer holds a claim the controller deleted until its composite and composed resources are gone
+	DeletionFinalizer = "platform.example.org/deletion-tracking"
+	// DeletionRefsAnnotation lists the composite and composed resources to wait for (JSON), recorded before the
+	// claim is deleted because the composite and its references go first
+	DeletionRefsAnnotation = "platform.example.org/deletion-refs"
+	// DeletionStuckAnnotation is when the claim was found stuck in deletion (RFC3339)
+	DeletionStuckAnnotation = "platform.example.org/deletion-stuck"
+
+	ReasonDeletionStuck = "DeletionStuck"
+
+	// deletionPollInterval is how often the resources of a deleting claim are checked, they are not watched
+	deletionPollInterval = 30 * time.Second
+)
+
+// deleting tracks the claims stuck in deletion for the DeletionsStuck gauge, the zero value is ready to use
+type deleting struct {
+	mu    sync.Mutex
+	stuck map[string]bool
+}
+
+func (d *deleting) setStuck(key string, stuck bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stuck == nil {
+		d.stuck = map[string]bool{}
+	}
+	if stuck {
+		d.stuck[key] = true
+	} else {
+		delete(d.stuck, key)
+	}
+	DeletionsStuck.Set(float64(len(d.stuck)))
+}
+
+// reader reads composites and composed resources, which the manager does not cache
+func (r *ClaimReconciler) reader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// trackDeletion adds the DeletionFinalizer to claim before it is deleted, recording the resources to wait for
+func (r *ClaimReconciler) trackDeletion(ctx context.Context, claim *unstructured.Unstructured) error {
+	var refs []map[string]any
+	if ref, ok, _ := unstructured.NestedMap(claim.Object, "spec", "resourceRef"); ok {
+		refs = append(refs, ref)
+		composite, err := getObject(ctx, r.reader(), ref)
+		if err != nil {
+			return err
+		}
+		if composite != nil {
+			composed, _, _ := unstructured.NestedSlice(composite.Object, "spec", "resourceRefs")
+			for _, ref := range composed {
+				if ref, ok := ref.(map[string]any); ok {
+					refs = append(refs, ref)
+				}
+			}
+		}
+	}
+	b, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+
+	annotations := claim.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[DeletionRefsAnnotation] = string(b)
+	claim.SetAnnotations(annotations)
+	controllerutil.AddFinalizer(claim, DeletionFinalizer)
+	if err := r.Update(ctx, claim); err != nil {
+		return fmt.Errorf("failed to add the deletion finalizer: %w", err)
+	}
+	return nil
+}
+
+// reconcileDeletion follows a claim the controller deleted: the DeletionFinalizer is removed once its composite
+// and composed resources are gone, and claims still waiting for them after DeletionTimeout are flagged
+func (r *ClaimReconciler) reconcileDeletion(ctx context.Context, claim *unstructured.Unstructured, log logr.Logger) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(claim, DeletionFinalizer) {
+		// deleted by someone else, the controller does not wait for it
+		return ctrl.Result{}, nil
+	}
+	key := claim.GetKind() + "/" + claim.GetNamespace() + "/" + claim.GetName()
+	elapsed := r.now().Sub(claim.GetDeletionTimestamp().Time)
+
+	var refs []map[string]any
+	if v := claim.GetAnnotations()[DeletionRefsAnnotation]; v != "" {
+		if err := json.Unmarshal([]byte(v), &refs); err != nil {
+			// an edited annotation would otherwise hold the claim forever
+			log.Error(err, "ignoring invalid deletion refs annotation")
+		}
+	}
+	var remaining []string
+	for _, ref := range refs {
+		u, err := getObject(ctx, r.reader(), ref)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if u != nil {
+			remaining = append(remaining, u.GetKind()+" "+u.GetName())
+		}
+	}
+
+	if len(remaining) == 0 {
+		controllerutil.RemoveFinalizer(claim, DeletionFinalizer)
+		if err := r.Update(ctx, claim); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to remove the deletion finalizer: %w", err)
+		}
+		r.deleting.setStuck(key, false)
+		DeletionDuration.WithLabelValues(claim.GetKind()).Observe(elapsed.Seconds())
+		log.Info("deletion completed", "duration", elapsed.String())
+		return ctrl.Result{}, nil
+	}
+
+	if r.DeletionTimeout > 0 && elapsed >= r.DeletionTimeout {
+		r.deleting.setStuck(key, true)
+		if _, flagged := claim.GetAnnotations()[DeletionStuckAnnotation]; !flagged {
+			r.event(claim, corev1.EventTypeWarning, ReasonDeletionStuck,
+				"Still deleting after %s, waiting for %s", elapsed.Round(time.Second), strings.Join(remaining, ", "))
+			log.Info("stuck in deletion", "elapsed", elapsed.String(), "remaining", remaining)
+			patch := map[string]any{}
+			setAnnotations(patch, map[string]any{DeletionStuckAnnotation: r.now().Local().Format(time.RFC3339)})
+			if err := r.mergePatch(ctx, claim, patch); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to flag the claim stuck in deletion: %w", err)
+			}
+		}
+	}
+	return ctrl.Result{RequeueAfter: deletionPollInterval}, nil
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newDeletionClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := newTestScheme()
+	scheme.AddKnownTypeWithName(compositeGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(bucketGVK, &unstructured.Unstructured{})
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func claimGone(t *testing.T, c client.Client, name string) bool {
+	t.Helper()
+	err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, &FakeClaim{})
+	return apierrors.IsNotFound(err)
+}
+
+func TestClaimReconciler_TrackDeletion(t *testing.T) {
+	claim := newTestClaim("bucket", map[string]string{CreationAnnotation: time.Now().Add(-time.Hour).Local().Format(time.RFC3339)})
+	claim.Spec = map[string]any{
+		"resourceRef": map[string]any{"apiVersion": compositeGVK.GroupVersion().String(), "kind": "AWSStorage", "name": "bucket-x7k2p"},
+	}
+	composite := newTestObject(compositeGVK, "bucket-x7k2p", map[string]any{"resourceRefs": []any{
+		map[string]any{"apiVersion": bucketGVK.GroupVersion().String(), "kind": "Bucket", "name": "bucket-x7k2p-s3"},
+	}})
+	bucket := newTestObject(bucketGVK, "bucket-x7k2p-s3", nil)
+	c := newDeletionClient(t, claim, composite, bucket)
+	r, _ := newHibernateReconciler(c)
+	r.GracePeriod = 0
+	DeletionDuration.Reset()
+
+	require.Equal(t, deletionPollInterval, reconcileClaim(t, r, "bucket"))
+	deleting := getClaim(t, c, "bucket")
+	require.NotNil(t, deleting.DeletionTimestamp)
+	require.Contains(t, deleting.Finalizers, DeletionFinalizer)
+	var refs []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(deleting.Annotations[DeletionRefsAnnotation]), &refs))
+	require.Len(t, refs, 2, "the composite and the bucket")
+	require.Equal(t, float64(1), testutil.ToFloat64(DeletedClaims.WithLabelValues()))
+
+	// the composite goes first, the bucket is still being deleted at AWS
+	require.NoError(t, c.Delete(context.Background(), composite))
+	require.Equal(t, deletionPollInterval, reconcileClaim(t, r, "bucket"))
+	require.False(t, claimGone(t, c, "bucket"))
+	require.Equal(t, 0, testutil.CollectAndCount(DeletionDuration))
+
+	require.NoError(t, c.Delete(context.Background(), bucket))
+	require.Zero(t, reconcileClaim(t, r, "bucket"))
+	require.True(t, claimGone(t, c, "bucket"), "the finalizer is removed once the resources are gone")
+	require.Equal(t, 1, testutil.CollectAndCount(DeletionDuration))
+}
+
+func TestClaimReconciler_DeletionStuck(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	deletedAt := metav1.NewTime(now.Add(-2 * time.Hour))
+	claim := newTestClaim("stuck", map[string]string{
+		DeletionRefsAnnotation: `[{"apiVersion": "s3.aws.upbound.io/v1beta1", "kind": "Bucket", "name": "stuck-s3"}]`,
+	})
+	claim.Finalizers = []string{DeletionFinalizer}
+	claim.DeletionTimestamp = &deletedAt
+	bucket := newTestObject(bucketGVK, "stuck-s3", nil)
+	c := newDeletionClient(t, claim, bucket)
+	r, recorder := newHibernateReconciler(c)
+	r.DeletionTimeout = time.Hour
+	r.Clock = clocktesting.NewFakePassiveClock(now)
+	DeletionsStuck.Set(0)
+
+	require.Equal(t, deletionPollInterval, reconcileClaim(t, r, "stuck"))
+	event := <-recorder.Events
+	require.True(t, strings.HasPrefix(event, "Warning DeletionStuck Still deleting after 2h0m"), event)
+	require.Contains(t, event, "waiting for Bucket stuck-s3")
+	require.Equal(t, now.Local().Format(time.RFC3339), getClaim(t, c, "stuck").Annotations[DeletionStuckAnnotation])
+	require.Equal(t, float64(1), testutil.ToFloat64(DeletionsStuck))
+
+	// flagged once
+	reconcileClaim(t, r, "stuck")
+	require.Empty(t, recorder.Events)
+
+	require.NoError(t, c.Delete(context.Background(), bucket))
+	reconcileClaim(t, r, "stuck")
+	require.True(t, claimGone(t, c, "stuck"))
+	require.Equal(t, float64(0), testutil.ToFloat64(DeletionsStuck))
+}
+
+func TestClaimReconciler_DeletedByOthers(t *testing.T) {
+	deletedAt := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	claim := newTestClaim("manual", nil)
+	claim.Finalizers = []string{"finalizer.apiextensions.crossplane.io"}
+	claim.DeletionTimestamp = &deletedAt
+	c := newDeletionClient(t, claim)
+	r, recorder := newHibernateReconciler(c)
+	r.DeletionTimeout = time.Hour
+
+	require.Zero(t, reconcileClaim(t, r, "manual"))
+	require.Empty(t, recorder.Events, "only the deletions of the controller are followed")
+	require.Equal(t, []string{"finalizer.apiextensions.crossplane.io"}, getClaim(t, c, "manual").Finalizers)
+}
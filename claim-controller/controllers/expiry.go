@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ExpiresAtAnnotation is the time the claim is deleted at (RFC3339) given its current TTL, idle timeout and grace
+	// period, kept up to date on every reconcile for the api-server to display
+	ExpiresAtAnnotation = "platform.example.org/expires-at"
+	// ExpiringSoonAnnotation is the time the claim is deleted at (RFC3339), set once the first warning is due
+	ExpiringSoonAnnotation = "platform.example.org/expiring-soon"
+	// ExpiryWarningAnnotation is the lead time of the last warning, so each one is only issued once
+	ExpiryWarningAnnotation = "platform.example.org/expiry-warning"
+
+	ReasonExpiringSoon = "ExpiringSoon"
+)
+
+// ParseLeadTimes parses the lead times of expiry warnings, i.e. 24h,1h,10m, sorted longest first
+func ParseLeadTimes(s string) ([]time.Duration, error) {
+	var leads []time.Duration
+	if s == "" {
+		return leads, nil
+	}
+	for _, v := range strings.Split(s, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid lead time %q, expected a positive duration", v)
+		}
+		leads = append(leads, d)
+	}
+	slices.Sort(leads)
+	slices.Reverse(leads)
+	return slices.Compact(leads), nil
+}
+
+// warnExpiry records deleteAt in ExpiresAtAnnotation and issues the expiry warning due for the claim: an
+// ExpiringSoon Event and the ExpiringSoonAnnotation and ExpiryWarningAnnotation. Lead times as long as the lifetime of the claim are skipped,
+// so a claim is not warned as it is created, and a claim extended past every lead time loses its annotations.
+// It returns when the next warning is due after now, zero when none is.
+func (r *ClaimReconciler) warnExpiry(ctx context.Context, claim *unstructured.Unstructured, lifetime time.Duration, deleteAt, now time.Time) (time.Duration, error) {
+	remaining := deleteAt.Sub(now)
+	var due, next time.Duration
+	for _, lead := range r.WarnBefore { // longest first
+		if lead >= lifetime {
+			continue
+		}
+		if remaining <= lead {
+			due = lead
+		} else if next == 0 {
+			next = remaining - lead
+		}
+	}
+
+	annotations := claim.GetAnnotations()
+	expiresAt := deleteAt.Local().Format(time.RFC3339)
+	warned := annotations[ExpiryWarningAnnotation]
+	switch {
+	case annotations[ExpiresAtAnnotation] != expiresAt: // extended, idle or new
+	case due == 0 && warned == "" && annotations[ExpiringSoonAnnotation] == "":
+		return next, nil
+	case due != 0 && warned == due.String() && annotations[ExpiringSoonAnnotation] == expiresAt:
+		return next, nil
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &unstructured.Unstructured{}
+		latest.SetGroupVersionKind(claim.GroupVersionKind())
+		if err := r.Get(ctx, client.ObjectKeyFromObject(claim), latest); err != nil {
+			return err
+		}
+		annotations := latest.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[ExpiresAtAnnotation] = expiresAt
+		if due == 0 {
+			delete(annotations, ExpiringSoonAnnotation)
+			delete(annotations, ExpiryWarningAnnotation)
+		} else {
+			annotations[ExpiringSoonAnnotation] = expiresAt
+			annotations[ExpiryWarningAnnotation] = due.String()
+		}
+		latest.SetAnnotations(annotations)
+		return r.Update(ctx, latest)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to update the expiry annotations: %w", err)
+	}
+
+	if due != 0 && warned != due.String() {
+		r.event(claim, corev1.EventTypeWarning, ReasonExpiringSoon,
+			"The claim is deleted at %s, in %s; extend it to keep it", expiresAt, remaining.Round(time.Second))
+		ExpiryWarnings.WithLabelValues(due.String()).Inc()
+	}
+	return next, nil
+}
+
+// clearExpiry removes the expiry annotations of a claim exempted from expiry
+func (r *ClaimReconciler) clearExpiry(ctx context.Context, claim *unstructured.Unstructured) error {
+	annotations := claim.GetAnnotations()
+	if annotations[ExpiresAtAnnotation] == "" && annotations[ExpiringSoonAnnotation] == "" && annotations[ExpiryWarningAnnotation] == "" {
+		return nil
+	}
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &unstructured.Unstructured{}
+		latest.SetGroupVersionKind(claim.GroupVersionKind())
+		if err := r.Get(ctx, client.ObjectKeyFromObject(claim), latest); err != nil {
+			return err
+		}
+		annotations := latest.GetAnnotations()
+		delete(annotations, ExpiresAtAnnotation)
+		delete(annotations, ExpiringSoonAnnotation)
+		delete(annotations, ExpiryWarningAnnotation)
+		latest.SetAnnotations(annotations)
+		return r.Update(ctx, latest)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear the expiry annotations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func TestClaimReconciler_WarnExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-70 * time.Minute)
+	deleteAt := created.Add(2 * time.Hour).Local().Format(time.RFC3339)
+
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		expectEvent     bool
+		expectWarning   string
+		expectExpiresAt string
+		expectRequeue   time.Duration
+	}{
+		{
+			name:            "warns an hour before the deletion",
+			expectEvent:     true,
+			expectWarning:   "1h0m0s",
+			expectExpiresAt: deleteAt,
+			expectRequeue:   40 * time.Minute,
+		},
+		{
+			name:            "warns once",
+			annotations:     map[string]string{ExpiringSoonAnnotation: deleteAt, ExpiryWarningAnnotation: "1h0m0s"},
+			expectWarning:   "1h0m0s",
+			expectExpiresAt: deleteAt,
+			expectRequeue:   40 * time.Minute,
+		},
+		{
+			name:            "follows an earlier warning",
+			annotations:     map[string]string{ExpiringSoonAnnotation: deleteAt, ExpiryWarningAnnotation: "24h0m0s"},
+			expectEvent:     true,
+			expectWarning:   "1h0m0s",
+			expectExpiresAt: deleteAt,
+			expectRequeue:   40 * time.Minute,
+		},
+		{
+			name:            "clears the warning of an extended claim",
+			annotations:     map[string]string{TTLAnnotation: "8h", ExpiringSoonAnnotation: deleteAt, ExpiryWarningAnnotation: "1h0m0s"},
+			expectExpiresAt: created.Add(8 * time.Hour).Local().Format(time.RFC3339),
+			expectRequeue:   8*time.Hour - 70*time.Minute - time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{CreationAnnotation: created.Local().Format(time.RFC3339), TTLAnnotation: "2h"}
+			for k, v := range tt.annotations {
+				annotations[k] = v
+			}
+			claim := newTestClaim("claim", annotations)
+			c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(claim).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &ClaimReconciler{
+				Client:     c,
+				Log:        zap.New(zap.UseDevMode(true)),
+				Kinds:      NewClaimKinds(fakeGVK),
+				TTLSeconds: TTLSeconds,
+				Recorder:   recorder,
+				WarnBefore: []time.Duration{24 * time.Hour, time.Hour, 10 * time.Minute},
+				Clock:      clocktesting.NewFakePassiveClock(now),
+			}
+			ExpiryWarnings.Reset()
+
+			key := types.NamespacedName{Name: "claim", Namespace: "default"}
+			result, err := r.Reconcile(context.Background(), ClaimRequest{GVK: fakeGVK, NamespacedName: key})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectRequeue, result.RequeueAfter)
+
+			var updated FakeClaim
+			require.NoError(t, c.Get(context.Background(), key, &updated))
+			require.Equal(t, tt.expectWarning, updated.Annotations[ExpiryWarningAnnotation])
+			require.Equal(t, tt.expectExpiresAt, updated.Annotations[ExpiresAtAnnotation])
+			if tt.expectWarning != "" {
+				require.Equal(t, deleteAt, updated.Annotations[ExpiringSoonAnnotation])
+			} else {
+				require.NotContains(t, updated.Annotations, ExpiringSoonAnnotation)
+			}
+
+			if tt.expectEvent {
+				event := <-recorder.Events
+				require.True(t, strings.HasPrefix(event, "Warning ExpiringSoon The claim is deleted at "+deleteAt), event)
+				require.Equal(t, float64(1), testutil.ToFloat64(ExpiryWarnings.WithLabelValues(tt.expectWarning)))
+			} else {
+				require.Empty(t, recorder.Events)
+				require.Equal(t, 0, testutil.CollectAndCount(ExpiryWarnings))
+			}
+		})
+	}
+}
+
+func TestClaimReconciler_WarnExpirySkipsLongLeads(t *testing.T) {
+	claim := newTestClaim("new", map[string]string{CreationAnnotation: time.Now().Local().Format(time.RFC3339), TTLAnnotation: "30m"})
+	recorder := record.NewFakeRecorder(10)
+	r := &ClaimReconciler{
+		Client:     fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(claim).Build(),
+		Log:        zap.New(zap.UseDevMode(true)),
+		Kinds:      NewClaimKinds(fakeGVK),
+		TTLSeconds: TTLSeconds,
+		Recorder:   recorder,
+		WarnBefore: []time.Duration{24 * time.Hour, time.Hour, 10 * time.Minute},
+	}
+
+	result, err := r.Reconcile(context.Background(), ClaimRequest{GVK: fakeGVK, NamespacedName: types.NamespacedName{Name: "new", Namespace: "default"}})
+	require.NoError(t, err)
+	require.Empty(t, recorder.Events, "a claim is not warned as it is created")
+	require.InDelta(t, (20 * time.Minute).Seconds(), result.RequeueAfter.Seconds(), 2)
+}
+
+func TestParseLeadTimes(t *testing.T) {
+	leads, err := ParseLeadTimes("10m, 24h,1h,10m")
+	require.NoError(t, err)
+	require.Equal(t, []time.Duration{24 * time.Hour, time.Hour, 10 * time.Minute}, leads)
+
+	leads, err = ParseLeadTimes("")
+	require.NoError(t, err)
+	require.Empty(t, leads)
+
+	for _, s := range []string{"soon", "0s", "1h,-10m"} {
+		_, err := ParseLeadTimes(s)
+		require.Error(t, err, s)
+	}
+}
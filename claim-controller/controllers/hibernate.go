@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"claim-controller/api/v1alpha1"
+)
+
+const (
+	// PhaseAnnotation is the lifecycle phase of an expired claim kept for its grace period
+	PhaseAnnotation  = "platform.example.org/phase"
+	PhaseHibernating = "Hibernating"
+	// HibernatedAtAnnotation is when the claim expired and was hibernated (RFC3339)
+	HibernatedAtAnnotation = "platform.example.org/hibernated-at"
+	// RestoreAnnotation is the JSON merge patch undoing the hibernation patch, applied when the claim is revived
+	RestoreAnnotation = "platform.example.org/hibernate-restore"
+	// PausedAnnotation stops Crossplane from reconciling the claim, the default hibernation
+	PausedAnnotation = "crossplane.io/paused"
+
+	ReasonHibernated = "Hibernated"
+	ReasonRevived    = "Revived"
+)
+
+// pausePatch hibernates the claims of kinds without a patch in HibernatePatches
+var pausePatch = map[string]any{"metadata": map[string]any{"annotations": map[string]any{PausedAnnotation: "true"}}}
+
+// ParseHibernatePatches parses the JSON merge patches scaling down the claims of a kind while they hibernate,
+// keyed by kind, i.e. {"Compute": {"spec": {"instanceType": "t3.nano"}}}
+func ParseHibernatePatches(s string) (map[string]map[string]any, error) {
+	patches := map[string]map[string]any{}
+	if s == "" {
+		return patches, nil
+	}
+	if err := json.Unmarshal([]byte(s), &patches); err != nil {
+		return nil, fmt.Errorf("invalid hibernate patches: %w", err)
+	}
+	return patches, nil
+}
+
+// gracePeriod is how long expired claims hibernate before they are deleted, the policy overriding the default
+func (r *ClaimReconciler) gracePeriod(p *v1alpha1.ClaimLifecyclePolicy) time.Duration {
+	if p == nil || p.Spec.GracePeriod == nil {
+		return r.GracePeriod
+	}
+	return max(p.Spec.GracePeriod.Duration, 0)
+}
+
+func hibernating(claim client.Object) bool {
+	return claim.GetAnnotations()[PhaseAnnotation] == PhaseHibernating
+}
+
+// hibernate pauses an expired claim, or applies the scale-down patch of its kind, recording how to undo it; cause
+// tells why it expired
+func (r *ClaimReconciler) hibernate(ctx context.Context, claim *unstructured.Unstructured, cause string, deleteAt time.Time) error {
+	patch, ok := r.HibernatePatches[claim.GetKind()]
+	if !ok {
+		patch = pausePatch
+	}
+	restore, err := json.Marshal(reversePatch(claim.Object, patch))
+	if err != nil {
+		return err
+	}
+	patch = runtime.DeepCopyJSON(patch)
+	setAnnotations(patch, map[string]any{
+		PhaseAnnotation:        PhaseHibernating,
+		HibernatedAtAnnotation: r.now().Local().Format(time.RFC3339),
+		RestoreAnnotation:      string(restore),
+	})
+	if err := r.mergePatch(ctx, claim, patch); err != nil {
+		return fmt.Errorf("failed to hibernate: %w", err)
+	}
+	r.event(claim, corev1.EventTypeWarning, ReasonHibernated,
+		"%s, the claim is hibernated and deleted at %s; extend it to revive it", cause, deleteAt.Local().Format(time.RFC3339))
+	HibernatedClaims.WithLabelValues().Inc()
+	return nil
+}
+
+// revive undoes the hibernation of a claim whose TTL was extended or that was used again, as cause tells
+func (r *ClaimReconciler) revive(ctx context.Context, claim *unstructured.Unstructured, cause string) error {
+	restore, err := restorePatch(claim)
+	if err != nil {
+		return err
+	}
+	setAnnotations(restore, map[string]any{PhaseAnnotation: nil, HibernatedAtAnnotation: nil, RestoreAnnotation: nil})
+	if err := r.mergePatch(ctx, claim, restore); err != nil {
+		return fmt.Errorf("failed to revive: %w", err)
+	}
+	r.event(claim, corev1.EventTypeNormal, ReasonRevived, "%s, the claim is revived", cause)
+	RevivedClaims.WithLabelValues().Inc()
+	return nil
+}
+
+// unpause lets Crossplane process the deletion of a claim paused by hibernate, it would hold it otherwise
+func (r *ClaimReconciler) unpause(ctx context.Context, claim *unstructured.Unstructured) error {
+	restore, err := restorePatch(claim)
+	if err != nil {
+		return err
+	}
+	annotations, _, _ := unstructured.NestedMap(restore, "metadata", "annotations")
+	paused, ok := annotations[PausedAnnotation]
+	if !ok {
+		return nil
+	}
+	patch := map[string]any{}
+	setAnnotations(patch, map[string]any{PausedAnnotation: paused})
+	return r.mergePatch(ctx, claim, patch)
+}
+
+func restorePatch(claim *unstructured.Unstructured) (map[string]any, error) {
+	restore := map[string]any{}
+	if err := json.Unmarshal([]byte(claim.GetAnnotations()[RestoreAnnotation]), &restore); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", RestoreAnnotation, err)
+	}
+	return restore, nil
+}
+
+func (r *ClaimReconciler) mergePatch(ctx context.Context, claim *unstructured.Unstructured, patch map[string]any) error {
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	return r.Patch(ctx, claim, client.RawPatch(types.MergePatchType, b))
+}
+
+// setAnnotations merges annotations into a merge patch, nil values removing them
+func setAnnotations(patch map[string]any, annotations map[string]any) {
+	metadata, _ := patch["metadata"].(map[string]any)
+	if metadata == nil {
+		metadata = map[string]any{}
+		patch["metadata"] = metadata
+	}
+	existing, _ := metadata["annotations"].(map[string]any)
+	if existing == nil {
+		existing = map[string]any{}
+		metadata["annotations"] = existing
+	}
+	for k, v := range annotations {
+		existing[k] = v
+	}
+}
+
+// reversePatch is the merge patch restoring the fields of obj that patch sets, nil removing fields it adds
+func reversePatch(obj, patch map[string]any) map[string]any {
+	reverse := make(map[string]any, len(patch))
+	for k, v := range patch {
+		current, exists := obj[k]
+		nested, isMap := v.(map[string]any)
+		currentMap, currentIsMap := current.(map[string]any)
+		switch {
+		case isMap && currentIsMap:
+			reverse[k] = reversePatch(currentMap, nested)
+		case exists:
+			reverse[k] = runtime.DeepCopyJSONValue(current)
+		default:
+			reverse[k] = nil
+		}
+	}
+	return reverse
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func newHibernateReconciler(c client.Client) (*ClaimReconciler, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(10)
+	HibernatedClaims.Reset()
+	RevivedClaims.Reset()
+	DeletedClaims.Reset()
+	return &ClaimReconciler{
+		Client:      c,
+		Log:         zap.New(zap.UseDevMode(true)),
+		Kinds:       NewClaimKinds(fakeGVK),
+		TTLSeconds:  TTLSeconds,
+		Recorder:    recorder,
+		GracePeriod: time.Hour,
+	}, recorder
+}
+
+func reconcileClaim(t *testing.T, r *ClaimReconciler, name string) time.Duration {
+	t.Helper()
+	result, err := r.Reconcile(context.Background(), ClaimRequest{GVK: fakeGVK, NamespacedName: types.NamespacedName{Name: name, Namespace: "default"}})
+	require.NoError(t, err)
+	return result.RequeueAfter
+}
+
+func getClaim(t *testing.T, c client.Client, name string) *FakeClaim {
+	t.Helper()
+	var claim FakeClaim
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, &claim))
+	return &claim
+}
+
+func TestClaimReconciler_HibernateAndRevive(t *testing.T) {
+	created := time.Now().Add(-time.Hour).Local().Format(time.RFC3339)
+	claim := newTestClaim("paused", map[string]string{CreationAnnotation: created, TTLAnnotation: "30m"})
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(claim).Build()
+	r, recorder := newHibernateReconciler(c)
+
+	requeue := reconcileClaim(t, r, "paused")
+	require.InDelta(t, (30 * time.Minute).Seconds(), requeue.Seconds(), 2, "deleted once the grace period elapsed")
+	hibernated := getClaim(t, c, "paused")
+	require.Equal(t, PhaseHibernating, hibernated.Annotations[PhaseAnnotation])
+	require.Equal(t, "true", hibernated.Annotations[PausedAnnotation])
+	require.JSONEq(t, `{"metadata": {"annotations": {"crossplane.io/paused": null}}}`, hibernated.Annotations[RestoreAnnotation])
+	require.NotEmpty(t, hibernated.Annotations[HibernatedAtAnnotation])
+	require.True(t, strings.HasPrefix(<-recorder.Events, "Warning Hibernated "))
+	require.Equal(t, float64(1), testutil.ToFloat64(HibernatedClaims.WithLabelValues()))
+
+	// hibernating once
+	reconcileClaim(t, r, "paused")
+	require.Empty(t, recorder.Events)
+
+	// the owner extends the claim
+	hibernated.Annotations[TTLAnnotation] = "4h"
+	require.NoError(t, c.Update(context.Background(), hibernated))
+	reconcileClaim(t, r, "paused")
+	revived := getClaim(t, c, "paused")
+	for _, a := range []string{PhaseAnnotation, PausedAnnotation, RestoreAnnotation, HibernatedAtAnnotation} {
+		require.NotContains(t, revived.Annotations, a)
+	}
+	require.Equal(t, "Normal Revived TTL extended, the claim is revived", <-recorder.Events)
+	require.Equal(t, float64(1), testutil.ToFloat64(RevivedClaims.WithLabelValues()))
+}
+
+func TestClaimReconciler_HibernatePatch(t *testing.T) {
+	created := time.Now().Add(-time.Hour).Local().Format(time.RFC3339)
+	claim := newTestClaim("scaled", map[string]string{CreationAnnotation: created, TTLAnnotation: "30m"})
+	claim.Spec = map[string]any{"size": "large"}
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(claim).Build()
+	r, _ := newHibernateReconciler(c)
+	r.HibernatePatches = map[string]map[string]any{"Storage": {"spec": map[string]any{"size": "small", "replicas": "0"}}}
+
+	reconcileClaim(t, r, "scaled")
+	hibernated := getClaim(t, c, "scaled")
+	require.Equal(t, map[string]any{"size": "small", "replicas": "0"}, hibernated.Spec)
+	require.NotContains(t, hibernated.Annotations, PausedAnnotation, "scaled down rather than paused")
+
+	hibernated.Annotations[TTLAnnotation] = "4h"
+	require.NoError(t, c.Update(context.Background(), hibernated))
+	reconcileClaim(t, r, "scaled")
+	require.Equal(t, map[string]any{"size": "large"}, getClaim(t, c, "scaled").Spec)
+}
+
+func TestClaimReconciler_DeleteHibernated(t *testing.T) {
+	created := time.Now().Add(-2 * time.Hour).Local().Format(time.RFC3339)
+	claim := newTestClaim("expired", map[string]string{
+		CreationAnnotation: created,
+		TTLAnnotation:      "30m",
+		PhaseAnnotation:    PhaseHibernating,
+		PausedAnnotation:   "true",
+		RestoreAnnotation:  `{"metadata": {"annotations": {"crossplane.io/paused": null}}}`,
+	})
+	var pausedOnDelete bool
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(claim).WithInterceptorFuncs(interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			_, pausedOnDelete = obj.GetAnnotations()[PausedAnnotation]
+			return c.Delete(ctx, obj, opts...)
+		},
+	}).Build()
+	r, _ := newHibernateReconciler(c)
+
+	reconcileClaim(t, r, "expired")
+	require.False(t, pausedOnDelete, "Crossplane does not delete paused claims")
+	err := c.Get(context.Background(), types.NamespacedName{Name: "expired", Namespace: "default"}, &FakeClaim{})
+	require.True(t, apierrors.IsNotFound(err))
+	require.Equal(t, float64(1), testutil.ToFloat64(DeletedClaims.WithLabelValues()))
+}
+
+func TestReversePatch(t *testing.T) {
+	obj := map[string]any{
+		"metadata": map[string]any{"annotations": map[string]any{"crossplane.io/paused": "false"}},
+		"spec":     map[string]any{"size": "large", "tags": map[string]any{"team": "data"}},
+	}
+	patch := map[string]any{
+		"metadata": map[string]any{"annotations": map[string]any{"crossplane.io/paused": "true"}},
+		"spec":     map[string]any{"size": "small", "replicas": int64(0), "tags": "none"},
+	}
+	require.Equal(t, map[string]any{
+		"metadata": map[string]any{"annotations": map[string]any{"crossplane.io/paused": "false"}},
+		"spec":     map[string]any{"size": "large", "replicas": nil, "tags": map[string]any{"team": "data"}},
+	}, reversePatch(obj, patch))
+}
+
+func TestParseHibernatePatches(t *testing.T) {
+	patches, err := ParseHibernatePatches(`{"Compute": {"spec": {"instanceType": "t3.nano"}}}`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]any{"Compute": {"spec": map[string]any{"instanceType": "t3.nano"}}}, patches)
+
+	_, err = ParseHibernatePatches(`["Compute"]`)
+	require.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"claim-controller/api/v1alpha1"
+)
+
+const (
+	// LastActivityAnnotation is when the claim was last used (RFC3339), bumped by the api-server when the claim is
+	// viewed, extended or its credentials revealed, and by workloads through its activity API
+	LastActivityAnnotation = "platform.example.org/last-activity"
+
+	ReasonInvalidLastActivity = "InvalidLastActivity"
+)
+
+// idleTimeout is how long claims may go without activity before they expire, the policy overriding the default;
+// zero when they expire on their TTL only
+func (r *ClaimReconciler) idleTimeout(p *v1alpha1.ClaimLifecyclePolicy) time.Duration {
+	if p == nil || p.Spec.IdleTimeout == nil {
+		return r.IdleTimeout
+	}
+	return max(p.Spec.IdleTimeout.Duration, 0)
+}
+
+// lastActivity is the time of LastActivityAnnotation, or else since when the claim exists
+func (r *ClaimReconciler) lastActivity(claim client.Object, creationTime time.Time) time.Time {
+	v, ok := claim.GetAnnotations()[LastActivityAnnotation]
+	if !ok {
+		return creationTime
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		r.event(claim, corev1.EventTypeWarning, ReasonInvalidLastActivity,
+			"Ignoring %s %q, expected an RFC3339 time; the claim is idle since its creation", LastActivityAnnotation, v)
+		return creationTime
+	}
+	if t.Before(creationTime) {
+		return creationTime
+	}
+	return t
+}
+
+// revivedBy tells why a hibernated claim is revived: activity recorded since it hibernated, or else its TTL
+// extended
+func revivedBy(claim client.Object, lastActivity time.Time) string {
+	if hibernatedAt, err := time.Parse(time.RFC3339, claim.GetAnnotations()[HibernatedAtAnnotation]); err == nil &&
+		lastActivity.After(hibernatedAt) {
+		return "Activity recorded"
+	}
+	return "TTL extended"
+}
+
+// ttlBuckets and idleBuckets bound the lifetime histograms, in seconds: 10m to a week, and 5m to 3 days
+var (
+	ttlBuckets  = []float64{600, 3600, 4 * 3600, 8 * 3600, 24 * 3600, 72 * 3600, 168 * 3600}
+	idleBuckets = []float64{300, 900, 3600, 4 * 3600, 12 * 3600, 24 * 3600, 72 * 3600}
+)
+
+type lifetime struct {
+	kind         string
+	ttl          time.Duration
+	lastActivity time.Time
+}
+
+// lifetimes tracks the TTL and last activity of the claims under expiry, collected as histograms by kind so the
+// series stay bounded by the claim kinds however many claims there are. Idle times are measured on scrape.
+type lifetimes struct {
+	ttl, idle *prometheus.Desc
+	// clock measures idle times, the real clock when nil
+	clock clock.PassiveClock
+
+	mu     sync.Mutex
+	claims map[string]lifetime // keyed by kind/namespace/name
+}
+
+// observe records the lifetime of claim, replacing the previous one
+func (l *lifetimes) observe(claim client.Object, ttl time.Duration, lastActivity time.Time) {
+	kind := claim.GetObjectKind().GroupVersionKind().Kind
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.claims == nil {
+		l.claims = map[string]lifetime{}
+	}
+	l.claims[kind+"/"+claim.GetNamespace()+"/"+claim.GetName()] = lifetime{kind: kind, ttl: ttl, lastActivity: lastActivity}
+}
+
+// forget drops a claim that is gone, being deleted or exempted from expiry
+func (l *lifetimes) forget(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.claims, key)
+}
+
+// reset forgets every claim
+func (l *lifetimes) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.claims = nil
+}
+
+func (l *lifetimes) Describe(ch chan<- *prometheus.Desc) {
+	ch <- l.ttl
+	ch <- l.idle
+}
+
+func (l *lifetimes) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	if l.clock != nil {
+		now = l.clock.Now()
+	}
+	ttls, idles := map[string][]float64{}, map[string][]float64{}
+	l.mu.Lock()
+	for _, c := range l.claims {
+		ttls[c.kind] = append(ttls[c.kind], c.ttl.Seconds())
+		idles[c.kind] = append(idles[c.kind], max(now.Sub(c.lastActivity), 0).Seconds())
+	}
+	l.mu.Unlock()
+	for kind, values := range ttls {
+		ch <- histogram(l.ttl, ttlBuckets, values, kind)
+		ch <- histogram(l.idle, idleBuckets, idles[kind], kind)
+	}
+}
+
+func histogram(desc *prometheus.Desc, buckets, values []float64, labels ...string) prometheus.Metric {
+	counts := make(map[float64]uint64, len(buckets))
+	for _, b := range buckets {
+		counts[b] = 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+		for _, b := range buckets {
+			if v <= b {
+				counts[b]++
+			}
+		}
+	}
+	return prometheus.MustNewConstHistogram(desc, uint64(len(values)), sum, counts, labels...)
+}
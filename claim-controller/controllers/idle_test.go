@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"claim-controller/api/v1alpha1"
+)
+
+func TestClaimReconciler_Idle(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	clock := clocktesting.NewFakePassiveClock(now)
+	claim := newTestClaim("idle", map[string]string{
+		CreationAnnotation:     now.Add(-3 * time.Hour).Format(time.RFC3339),
+		TTLAnnotation:          "72h",
+		LastActivityAnnotation: now.Add(-90 * time.Minute).Format(time.RFC3339),
+	})
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(claim).Build()
+	r, recorder := newHibernateReconciler(c)
+	r.Clock = clock
+	r.IdleTimeout = time.Hour
+	ClaimLifetimes.reset()
+	ClaimLifetimes.clock = clock
+	t.Cleanup(func() { ClaimLifetimes.clock = nil })
+
+	require.Equal(t, 30*time.Minute, reconcileClaim(t, r, "idle"), "deleted once the grace period elapsed")
+	hibernated := getClaim(t, c, "idle")
+	require.Equal(t, PhaseHibernating, hibernated.Annotations[PhaseAnnotation])
+	deleteAt := now.Add(30 * time.Minute).Local().Format(time.RFC3339)
+	require.Equal(t, "Warning Hibernated Idle for 1h0m0s, the claim is hibernated and deleted at "+deleteAt+"; extend it to revive it", <-recorder.Events)
+	require.NoError(t, testutil.CollectAndCompare(ClaimLifetimes, strings.NewReader(`
+		# HELP claim_idle_seconds Time since the last activity on the Claims under expiry, or their creation when none was recorded, by kind
+		# TYPE claim_idle_seconds histogram
+		claim_idle_seconds_bucket{kind="Storage",le="300"} 0
+		claim_idle_seconds_bucket{kind="Storage",le="900"} 0
+		claim_idle_seconds_bucket{kind="Storage",le="3600"} 0
+		claim_idle_seconds_bucket{kind="Storage",le="14400"} 1
+		claim_idle_seconds_bucket{kind="Storage",le="43200"} 1
+		claim_idle_seconds_bucket{kind="Storage",le="86400"} 1
+		claim_idle_seconds_bucket{kind="Storage",le="259200"} 1
+		claim_idle_seconds_bucket{kind="Storage",le="+Inf"} 1
+		claim_idle_seconds_sum{kind="Storage"} 5400
+		claim_idle_seconds_count{kind="Storage"} 1
+	`), "claim_idle_seconds"))
+	require.NoError(t, testutil.CollectAndCompare(ClaimLifetimes, strings.NewReader(`
+		# HELP claim_ttl_seconds Lifetime of the Claims under expiry, requested or default, by kind
+		# TYPE claim_ttl_seconds histogram
+		claim_ttl_seconds_bucket{kind="Storage",le="600"} 0
+		claim_ttl_seconds_bucket{kind="Storage",le="3600"} 0
+		claim_ttl_seconds_bucket{kind="Storage",le="14400"} 0
+		claim_ttl_seconds_bucket{kind="Storage",le="28800"} 0
+		claim_ttl_seconds_bucket{kind="Storage",le="86400"} 0
+		claim_ttl_seconds_bucket{kind="Storage",le="259200"} 1
+		claim_ttl_seconds_bucket{kind="Storage",le="604800"} 1
+		claim_ttl_seconds_bucket{kind="Storage",le="+Inf"} 1
+		claim_ttl_seconds_sum{kind="Storage"} 259200
+		claim_ttl_seconds_count{kind="Storage"} 1
+	`), "claim_ttl_seconds"))
+
+	// the owner opens the claim in the portal
+	clock.SetTime(now.Add(5 * time.Minute))
+	hibernated.Annotations[LastActivityAnnotation] = clock.Now().Format(time.RFC3339)
+	require.NoError(t, c.Update(context.Background(), hibernated))
+	require.Equal(t, time.Hour, reconcileClaim(t, r, "idle"))
+	require.NotContains(t, getClaim(t, c, "idle").Annotations, PhaseAnnotation)
+	require.Equal(t, "Normal Revived Activity recorded, the claim is revived", <-recorder.Events)
+
+	// idle again for the timeout and the grace period
+	clock.SetTime(now.Add(2*time.Hour + 5*time.Minute))
+	reconcileClaim(t, r, "idle")
+	require.True(t, claimGone(t, c, "idle"))
+	require.Equal(t, float64(1), testutil.ToFloat64(DeletedClaims.WithLabelValues()))
+	require.Zero(t, testutil.CollectAndCount(ClaimLifetimes), "deleted claims are forgotten")
+}
+
+func TestLifetimes_BoundedByKind(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	l := &lifetimes{ttl: ClaimLifetimes.ttl, idle: ClaimLifetimes.idle, clock: clocktesting.NewFakePassiveClock(now)}
+	for i := range 100 {
+		l.observe(newTestClaim(fmt.Sprintf("dev-%d", i), nil), time.Hour, now.Add(-time.Minute))
+	}
+	require.Equal(t, 2, testutil.CollectAndCount(l), "one ttl and one idle histogram for every claim kind")
+
+	for i := range 100 {
+		l.forget(fmt.Sprintf("Storage/default/dev-%d", i))
+	}
+	require.Zero(t, testutil.CollectAndCount(l))
+}
+
+func TestClaimReconciler_IdleTimeout(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	clock := clocktesting.NewFakePassiveClock(now)
+	created := now.Add(-90 * time.Minute)
+
+	tests := []struct {
+		name          string
+		lastActivity  string
+		policy        *v1alpha1.ClaimLifecyclePolicy
+		expectRequeue time.Duration
+		expectEvent   string
+	}{
+		{
+			name:          "recent activity",
+			lastActivity:  now.Add(-10 * time.Minute).Format(time.RFC3339),
+			expectRequeue: 50 * time.Minute,
+		},
+		{
+			name:          "no activity recorded",
+			expectRequeue: 30 * time.Minute, // idle since its creation, hibernated for the grace period
+			expectEvent:   "Warning Hibernated Idle for 1h0m0s",
+		},
+		{
+			name:          "invalid activity",
+			lastActivity:  "yesterday",
+			expectRequeue: 30 * time.Minute,
+			expectEvent:   "Warning InvalidLastActivity Ignoring platform.example.org/last-activity \"yesterday\"",
+		},
+		{
+			name:          "policy timeout",
+			lastActivity:  now.Add(-10 * time.Minute).Format(time.RFC3339),
+			policy:        newTestPolicy("notebooks", v1alpha1.ClaimLifecyclePolicySpec{IdleTimeout: duration(4 * time.Hour)}),
+			expectRequeue: 3*time.Hour + 50*time.Minute,
+		},
+		{
+			name:          "disabled by the policy",
+			policy:        newTestPolicy("long-running", v1alpha1.ClaimLifecyclePolicySpec{IdleTimeout: duration(0)}),
+			expectRequeue: 70*time.Hour + 30*time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{CreationAnnotation: created.Format(time.RFC3339), TTLAnnotation: "72h"}
+			if tt.lastActivity != "" {
+				annotations[LastActivityAnnotation] = tt.lastActivity
+			}
+			objs := []client.Object{newTestClaim("dev", annotations)}
+			if tt.policy != nil {
+				objs = append(objs, tt.policy.DeepCopy())
+			}
+			c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(objs...).Build()
+			r, recorder := newHibernateReconciler(c)
+			r.Clock = clock
+			r.IdleTimeout = time.Hour
+
+			require.Equal(t, tt.expectRequeue, reconcileClaim(t, r, "dev"))
+			if tt.expectEvent == "" {
+				require.Empty(t, recorder.Events)
+				return
+			}
+			require.Contains(t, <-recorder.Events, tt.expectEvent)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ClaimKinds are the claim kinds the controller expires, keyed by the name of the XRD offering them
+type ClaimKinds struct {
+	mu    sync.RWMutex
+	byXRD map[string]schema.GroupVersionKind
+}
+
+// NewClaimKinds returns a registry holding gvks, registered under their kind
+func NewClaimKinds(gvks ...schema.GroupVersionKind) *ClaimKinds {
+	k := &ClaimKinds{byXRD: map[string]schema.GroupVersionKind{}}
+	for _, gvk := range gvks {
+		k.byXRD[gvk.Kind] = gvk
+	}
+	return k
+}
+
+// Add registers the claim kind offered by an XRD
+func (k *ClaimKinds) Add(xrd string, gvk schema.GroupVersionKind) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.byXRD[xrd] = gvk
+	WatchedKinds.Set(float64(len(k.byXRD)))
+}
+
+// Remove unregisters the claim kind of an XRD, returning it
+func (k *ClaimKinds) Remove(xrd string) (schema.GroupVersionKind, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	gvk, ok := k.byXRD[xrd]
+	delete(k.byXRD, xrd)
+	WatchedKinds.Set(float64(len(k.byXRD)))
+	return gvk, ok
+}
+
+// Get returns the claim kind registered for an XRD
+func (k *ClaimKinds) Get(xrd string) (schema.GroupVersionKind, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	gvk, ok := k.byXRD[xrd]
+	return gvk, ok
+}
+
+// List returns the registered claim kinds sorted by kind
+func (k *ClaimKinds) List() []schema.GroupVersionKind {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	gvks := make([]schema.GroupVersionKind, 0, len(k.byXRD))
+	for _, gvk := range k.byXRD {
+		gvks = append(gvks, gvk)
+	}
+	slices.SortFunc(gvks, func(a, b schema.GroupVersionKind) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Group, b.Group))
+	})
+	return gvks
+}
@@ -30,6 +30,77 @@ var (
 		[]string{},
 	)
 
+	ArchivedClaims = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "claims_archived_total",
+			Help: "Total number of expired Claims archived before deletion",
+		},
+		[]string{},
+	)
+
+	HibernatedClaims = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "claims_hibernated_total",
+			Help: "Total number of expired Claims hibernated for their grace period",
+		},
+		[]string{},
+	)
+
+	RevivedClaims = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "claims_revived_total",
+			Help: "Total number of hibernated Claims revived by extending their TTL or using them again",
+		},
+		[]string{},
+	)
+
+	ExpiryWarnings = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "claim_expiry_warnings_total",
+			Help: "Total number of warnings issued before Claims expire, by lead time",
+		},
+		[]string{"lead"},
+	)
+
+	DeletionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "claim_deletion_duration_seconds",
+			Help:    "Time from the deletion of expired Claims until their composite and composed resources are gone, by kind",
+			Buckets: prometheus.ExponentialBuckets(15, 2, 10), // 15s to ~2h
+		},
+		[]string{"kind"},
+	)
+
+	DeletionsStuck = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "claims_deletion_stuck",
+			Help: "Number of deleted Claims still waiting for their resources beyond the deletion timeout",
+		},
+	)
+
+	ScheduleTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "claim_schedule_transitions_total",
+			Help: "Total number of Claims paused outside or resumed within their active hours, by action",
+		},
+		[]string{"action"},
+	)
+
+	// ClaimLifetimes reports the TTL and idle time of the claims under expiry by kind, as histograms built on scrape
+	ClaimLifetimes = &lifetimes{
+		ttl: prometheus.NewDesc("claim_ttl_seconds",
+			"Lifetime of the Claims under expiry, requested or default, by kind", []string{"kind"}, nil),
+		idle: prometheus.NewDesc("claim_idle_seconds",
+			"Time since the last activity on the Claims under expiry, or their creation when none was recorded, by kind", []string{"kind"}, nil),
+	}
+
+	WatchedKinds = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "claim_kinds_watched",
+			Help: "Number of claim kinds offered by XRDs the controller watches",
+		},
+	)
+
 	ReconcileDuration = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Name:    "claim_reconcile_duration_seconds",
@@ -41,5 +112,5 @@ var (
 
 func RegisterMetrics() {
 	// controller-runtime runs its own Prometheus metrics server using a private registry
-	metrics.Registry.MustRegister(UpdatedClaims, DeletedClaims, SkippedClaims, ReconcileDuration)
+	metrics.Registry.MustRegister(UpdatedClaims, DeletedClaims, SkippedClaims, ArchivedClaims, HibernatedClaims, RevivedClaims, ExpiryWarnings, DeletionDuration, DeletionsStuck, ScheduleTransitions, ClaimLifetimes, WatchedKinds, ReconcileDuration)
 }
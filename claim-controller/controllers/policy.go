@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"claim-controller/api/v1alpha1"
+)
+
+// policy resolves the most specific ClaimLifecyclePolicy matching claim, nil when none does. Policies with an
+// invalid selector are skipped, ties go to the policy whose name sorts first.
+func (r *ClaimReconciler) policy(ctx context.Context, claim client.Object) (*v1alpha1.ClaimLifecyclePolicy, error) {
+	var policies v1alpha1.ClaimLifecyclePolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return nil, fmt.Errorf("failed to list lifecycle policies: %w", err)
+	}
+
+	var best *v1alpha1.ClaimLifecyclePolicy
+	bestScore := -1
+	for i := range policies.Items {
+		p := &policies.Items[i]
+		ok, err := matches(p, claim)
+		if err != nil {
+			r.Log.Error(err, "skipping lifecycle policy", "policy", p.Name)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if score := specificity(p); score > bestScore || (score == bestScore && p.Name < best.Name) {
+			best, bestScore = p, score
+		}
+	}
+	return best, nil
+}
+
+func matches(p *v1alpha1.ClaimLifecyclePolicy, claim client.Object) (bool, error) {
+	if len(p.Spec.Kinds) > 0 && !slices.Contains(p.Spec.Kinds, claim.GetObjectKind().GroupVersionKind().Kind) {
+		return false, nil
+	}
+	if len(p.Spec.Namespaces) > 0 && !slices.Contains(p.Spec.Namespaces, claim.GetNamespace()) {
+		return false, nil
+	}
+	if p.Spec.Selector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(p.Spec.Selector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(claim.GetLabels())), nil
+}
+
+func specificity(p *v1alpha1.ClaimLifecyclePolicy) int {
+	score := 0
+	if len(p.Spec.Namespaces) > 0 {
+		score += 4
+	}
+	if len(p.Spec.Kinds) > 0 {
+		score += 2
+	}
+	if p.Spec.Selector != nil && (len(p.Spec.Selector.MatchLabels) > 0 || len(p.Spec.Selector.MatchExpressions) > 0) {
+		score++
+	}
+	return score
+}
+
+// exempt reports whether an exemption of p matches the labels of claim
+func exempt(p *v1alpha1.ClaimLifecyclePolicy, claim client.Object) (bool, error) {
+	if p == nil {
+		return false, nil
+	}
+	for i := range p.Spec.Exemptions {
+		selector, err := metav1.LabelSelectorAsSelector(&p.Spec.Exemptions[i])
+		if err != nil {
+			return false, fmt.Errorf("invalid exemption of lifecycle policy %s: %w", p.Name, err)
+		}
+		if selector.Matches(labels.Set(claim.GetLabels())) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// claimsOf re-evaluates every claim when a policy changes: the claims it selects now and those it selected
+// before the change both have to pick their policy again
+func (r *ClaimReconciler) claimsOf(ctx context.Context, _ client.Object) []ClaimRequest {
+	var requests []ClaimRequest
+	for _, gvk := range r.Kinds.List() {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.List(ctx, list); err != nil {
+			r.Log.Error(err, "failed to list claims for a lifecycle policy change", "kind", gvk.Kind)
+			continue
+		}
+		for _, claim := range list.Items {
+			requests = append(requests, ClaimRequest{
+				GVK:            gvk,
+				NamespacedName: types.NamespacedName{Namespace: claim.GetNamespace(), Name: claim.GetName()},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"claim-controller/api/v1alpha1"
+)
+
+func newTestPolicy(name string, spec v1alpha1.ClaimLifecyclePolicySpec) *v1alpha1.ClaimLifecyclePolicy {
+	return &v1alpha1.ClaimLifecyclePolicy{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+}
+
+func duration(d time.Duration) *metav1.Duration {
+	return &metav1.Duration{Duration: d}
+}
+
+func newPolicyReconciler(objs ...client.Object) (*ClaimReconciler, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(10)
+	return &ClaimReconciler{
+		Client:     fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(objs...).Build(),
+		Kinds:      NewClaimKinds(fakeGVK),
+		Log:        zap.New(zap.UseDevMode(true)),
+		TTLSeconds: TTLSeconds,
+		Recorder:   recorder,
+	}, recorder
+}
+
+func TestClaimReconciler_Policy(t *testing.T) {
+	claim := newTestClaim("claim", nil)
+	claim.Labels = map[string]string{"platform.example.org/team": "data"}
+
+	tests := []struct {
+		name     string
+		policies []client.Object
+		expect   string
+	}{
+		{
+			name:   "no policy",
+			expect: "",
+		},
+		{
+			name: "skips policies not matching",
+			policies: []client.Object{
+				newTestPolicy("compute", v1alpha1.ClaimLifecyclePolicySpec{Kinds: []string{"Compute"}}),
+				newTestPolicy("bob", v1alpha1.ClaimLifecyclePolicySpec{Namespaces: []string{"bob"}}),
+				newTestPolicy("ml", v1alpha1.ClaimLifecyclePolicySpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"platform.example.org/team": "ml"}}}),
+			},
+			expect: "",
+		},
+		{
+			name: "prefers the namespace over the kind over the labels",
+			policies: []client.Object{
+				newTestPolicy("everything", v1alpha1.ClaimLifecyclePolicySpec{}),
+				newTestPolicy("data", v1alpha1.ClaimLifecyclePolicySpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"platform.example.org/team": "data"}}}),
+				newTestPolicy("storage", v1alpha1.ClaimLifecyclePolicySpec{Kinds: []string{"Storage"}}),
+				newTestPolicy("default", v1alpha1.ClaimLifecyclePolicySpec{Namespaces: []string{"default"}}),
+			},
+			expect: "default",
+		},
+		{
+			name: "breaks ties by name",
+			policies: []client.Object{
+				newTestPolicy("b", v1alpha1.ClaimLifecyclePolicySpec{Kinds: []string{"Storage"}}),
+				newTestPolicy("a", v1alpha1.ClaimLifecyclePolicySpec{Kinds: []string{"Storage", "Compute"}}),
+			},
+			expect: "a",
+		},
+		{
+			name: "skips an invalid selector",
+			policies: []client.Object{
+				newTestPolicy("everything", v1alpha1.ClaimLifecyclePolicySpec{}),
+				newTestPolicy("invalid", v1alpha1.ClaimLifecyclePolicySpec{Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Near"}}}}),
+			},
+			expect: "everything",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newPolicyReconciler(tt.policies...)
+			p, err := r.policy(context.Background(), claim)
+			require.NoError(t, err)
+			if tt.expect == "" {
+				require.Nil(t, p)
+				return
+			}
+			require.NotNil(t, p)
+			require.Equal(t, tt.expect, p.Name)
+		})
+	}
+}
+
+func TestClaimReconciler_PolicyTTL(t *testing.T) {
+	policy := newTestPolicy("storage", v1alpha1.ClaimLifecyclePolicySpec{DefaultTTL: duration(4 * time.Hour), MaxTTL: duration(8 * time.Hour)})
+	r, recorder := newPolicyReconciler()
+
+	require.Equal(t, 4*time.Hour, r.ttl(newTestClaim("default", nil), policy, r.Log))
+	require.Equal(t, 8*time.Hour, r.ttl(newTestClaim("capped", map[string]string{TTLAnnotation: "9h"}), policy, r.Log))
+	require.Equal(t, "Normal TTLClamped TTL of 9h0m0s exceeds the maximum of 8h0m0s set by lifecycle policy storage, the claim expires after 8h0m0s", <-recorder.Events)
+
+	r.MaxTTL = 6 * time.Hour
+	require.Equal(t, 6*time.Hour, r.ttl(newTestClaim("clamped", map[string]string{TTLAnnotation: "7h"}), policy, r.Log), "the admin maximum bounds policies")
+	require.Equal(t, "Normal TTLClamped TTL of 7h0m0s exceeds the maximum of 6h0m0s allowed by the platform, the claim expires after 6h0m0s", <-recorder.Events)
+}
+
+func TestClaimReconciler_ReconcileWithPolicy(t *testing.T) {
+	expired := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	pinned := newTestClaim("pinned", map[string]string{CreationAnnotation: expired, ExpiresAtAnnotation: time.Now().Format(time.RFC3339)})
+	pinned.Labels = map[string]string{"platform.example.org/pinned": "true"}
+
+	tests := []struct {
+		name          string
+		claim         client.Object
+		policy        v1alpha1.ClaimLifecyclePolicySpec
+		expectDeleted bool
+		expectExempt  bool
+		expectEvent   string
+	}{
+		{
+			name:   "extends the default ttl",
+			claim:  newTestClaim("extended", map[string]string{CreationAnnotation: expired}),
+			policy: v1alpha1.ClaimLifecyclePolicySpec{DefaultTTL: duration(2 * time.Hour)},
+		},
+		{
+			name:          "deletes after the default ttl",
+			claim:         newTestClaim("expired", map[string]string{CreationAnnotation: expired}),
+			policy:        v1alpha1.ClaimLifecyclePolicySpec{DefaultTTL: duration(30 * time.Minute)},
+			expectDeleted: true,
+		},
+		{
+			name:        "hibernates for the grace period",
+			claim:       newTestClaim("grace", map[string]string{CreationAnnotation: expired}),
+			policy:      v1alpha1.ClaimLifecyclePolicySpec{DefaultTTL: duration(30 * time.Minute), GracePeriod: duration(time.Hour)},
+			expectEvent: "Warning Hibernated",
+		},
+		{
+			name:         "exempts matching claims",
+			claim:        pinned,
+			policy:       v1alpha1.ClaimLifecyclePolicySpec{Exemptions: []metav1.LabelSelector{{MatchLabels: map[string]string{"platform.example.org/pinned": "true"}}}},
+			expectExempt: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, recorder := newPolicyReconciler(tt.claim, newTestPolicy("policy", tt.policy))
+			DeletedClaims.Reset()
+
+			_, err := r.Reconcile(context.Background(), ClaimRequest{GVK: fakeGVK, NamespacedName: types.NamespacedName{Name: tt.claim.GetName(), Namespace: "default"}})
+			require.NoError(t, err)
+
+			if tt.expectDeleted {
+				require.Equal(t, float64(1), testutil.ToFloat64(DeletedClaims.WithLabelValues()))
+			} else {
+				require.Equal(t, float64(0), testutil.ToFloat64(DeletedClaims.WithLabelValues()))
+				_, expiring := getClaim(t, r.Client, tt.claim.GetName()).Annotations[ExpiresAtAnnotation]
+				require.Equal(t, !tt.expectExempt, expiring, "exempted claims do not expire")
+			}
+			if tt.expectEvent == "" {
+				require.Empty(t, recorder.Events)
+			} else {
+				require.Contains(t, <-recorder.Events, tt.expectEvent+" ")
+			}
+		})
+	}
+}
+
+func TestClaimReconciler_ClaimsOf(t *testing.T) {
+	r, _ := newPolicyReconciler(newTestClaim("a", nil), newTestClaim("b", nil), newTestComputeClaim("a", nil))
+	r.Kinds = NewClaimKinds(fakeGVK, computeGVK)
+	requests := r.claimsOf(context.Background(), newTestPolicy("policy", v1alpha1.ClaimLifecyclePolicySpec{}))
+	require.ElementsMatch(t, []ClaimRequest{
+		{GVK: fakeGVK, NamespacedName: types.NamespacedName{Namespace: "default", Name: "a"}},
+		{GVK: fakeGVK, NamespacedName: types.NamespacedName{Namespace: "default", Name: "b"}},
+		{GVK: computeGVK, NamespacedName: types.NamespacedName{Namespace: "default", Name: "a"}},
+	}, requests)
+}
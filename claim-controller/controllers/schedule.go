@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"claim-controller/api/v1alpha1"
+	"claim-controller/schedule"
+)
+
+const (
+	// ScheduleStartAnnotation is the cron expression starting the active windows of a claim, i.e. 0 8 * * mon-fri
+	ScheduleStartAnnotation = "platform.example.org/schedule-start"
+	// ScheduleStopAnnotation is the cron expression stopping the active windows of a claim, i.e. 0 18 * * mon-fri
+	ScheduleStopAnnotation = "platform.example.org/schedule-stop"
+	// ScheduleTimeZoneAnnotation is the time zone of the schedule expressions (i.e. Europe/Paris), UTC by default
+	ScheduleTimeZoneAnnotation = "platform.example.org/schedule-timezone"
+	// SchedulePausedAnnotation is when the claim was paused outside its active windows (RFC3339)
+	SchedulePausedAnnotation = "platform.example.org/schedule-paused"
+
+	ReasonInvalidSchedule = "InvalidSchedule"
+	ReasonScheduledPause  = "ScheduledPause"
+	ReasonScheduledResume = "ScheduledResume"
+)
+
+func schedulePaused(claim client.Object) bool {
+	_, ok := claim.GetAnnotations()[SchedulePausedAnnotation]
+	return ok
+}
+
+// window is the schedule of the claim annotations, or else of the policy; nil when the claim runs all the time.
+// policy may be nil.
+func (r *ClaimReconciler) window(claim client.Object, policy *v1alpha1.ClaimLifecyclePolicy) *schedule.Window {
+	annotations := claim.GetAnnotations()
+	start, stop, tz := annotations[ScheduleStartAnnotation], annotations[ScheduleStopAnnotation], annotations[ScheduleTimeZoneAnnotation]
+	source := "claim annotations"
+	if start == "" && stop == "" {
+		if policy == nil || policy.Spec.Schedule == nil {
+			return nil
+		}
+		start, stop, tz = policy.Spec.Schedule.Start, policy.Spec.Schedule.Stop, policy.Spec.Schedule.TimeZone
+		source = "lifecycle policy " + policy.Name
+	}
+	w, err := schedule.ParseWindow(start, stop, tz)
+	if err != nil {
+		r.event(claim, corev1.EventTypeWarning, ReasonInvalidSchedule,
+			"Ignoring the schedule of the %s, the claim runs all the time: %v", source, err)
+		return nil
+	}
+	return w
+}
+
+// applySchedule pauses claim outside the active windows of its schedule and resumes it inside them, returning
+// how long until the schedule next changes, zero when it does not. Claims paused by someone else are left alone.
+func (r *ClaimReconciler) applySchedule(ctx context.Context, claim *unstructured.Unstructured, policy *v1alpha1.ClaimLifecyclePolicy, now time.Time) (time.Duration, error) {
+	w := r.window(claim, policy)
+	if w == nil {
+		if schedulePaused(claim) {
+			// the schedule was removed
+			return 0, r.resume(ctx, claim, "The schedule was removed, the claim is resumed")
+		}
+		return 0, nil
+	}
+
+	active, next := w.At(now)
+	_, paused := claim.GetAnnotations()[PausedAnnotation]
+	switch {
+	case !active && !paused:
+		patch := map[string]any{}
+		setAnnotations(patch, map[string]any{PausedAnnotation: "true", SchedulePausedAnnotation: now.Local().Format(time.RFC3339)})
+		if err := r.mergePatch(ctx, claim, patch); err != nil {
+			return 0, fmt.Errorf("failed to pause outside the active hours: %w", err)
+		}
+		until := "the schedule changes"
+		if !next.IsZero() {
+			until = next.In(w.Location).Format(time.RFC3339)
+		}
+		r.event(claim, corev1.EventTypeNormal, ReasonScheduledPause, "Outside the active hours of the claim, paused until %s", until)
+		ScheduleTransitions.WithLabelValues("pause").Inc()
+	case active && schedulePaused(claim):
+		if err := r.resume(ctx, claim, "Within the active hours of the claim, resumed"); err != nil {
+			return 0, err
+		}
+	}
+	if next.IsZero() {
+		return 0, nil
+	}
+	return next.Sub(now), nil
+}
+
+func (r *ClaimReconciler) resume(ctx context.Context, claim *unstructured.Unstructured, message string) error {
+	if err := r.unpauseSchedule(ctx, claim); err != nil {
+		return fmt.Errorf("failed to resume: %w", err)
+	}
+	r.event(claim, corev1.EventTypeNormal, ReasonScheduledResume, message)
+	ScheduleTransitions.WithLabelValues("resume").Inc()
+	return nil
+}
+
+// unpauseSchedule removes the pause of the schedule, before deleting the claim as well
+func (r *ClaimReconciler) unpauseSchedule(ctx context.Context, claim *unstructured.Unstructured) error {
+	patch := map[string]any{}
+	setAnnotations(patch, map[string]any{PausedAnnotation: nil, SchedulePausedAnnotation: nil})
+	return r.mergePatch(ctx, claim, patch)
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"claim-controller/api/v1alpha1"
+)
+
+func newScheduledClaim(name string, clock *clocktesting.FakePassiveClock, annotations map[string]string) *FakeClaim {
+	claim := newTestClaim(name, map[string]string{
+		CreationAnnotation: clock.Now().Add(-time.Hour).Format(time.RFC3339),
+		TTLAnnotation:      "72h",
+	})
+	for k, v := range annotations {
+		claim.Annotations[k] = v
+	}
+	return claim
+}
+
+var officeHours = map[string]string{
+	ScheduleStartAnnotation:    "0 8 * * mon-fri",
+	ScheduleStopAnnotation:     "0 18 * * mon-fri",
+	ScheduleTimeZoneAnnotation: "Europe/Paris",
+}
+
+func TestClaimReconciler_Schedule(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	clock := clocktesting.NewFakePassiveClock(time.Date(2026, 10, 19, 19, 0, 0, 0, paris)) // Monday evening
+	claim := newScheduledClaim("dev", clock, officeHours)
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(claim).Build()
+	r, recorder := newHibernateReconciler(c)
+	r.Clock = clock
+	ScheduleTransitions.Reset()
+
+	require.Equal(t, 13*time.Hour, reconcileClaim(t, r, "dev"), "resumed Tuesday morning")
+	paused := getClaim(t, c, "dev")
+	require.Equal(t, "true", paused.Annotations[PausedAnnotation])
+	require.NotEmpty(t, paused.Annotations[SchedulePausedAnnotation])
+	require.Equal(t, "Normal ScheduledPause Outside the active hours of the claim, paused until 2026-10-20T08:00:00+02:00", <-recorder.Events)
+
+	// paused once
+	reconcileClaim(t, r, "dev")
+	require.Empty(t, recorder.Events)
+
+	clock.SetTime(time.Date(2026, 10, 20, 8, 0, 0, 0, paris))
+	require.Equal(t, 10*time.Hour, reconcileClaim(t, r, "dev"), "paused Tuesday evening")
+	resumed := getClaim(t, c, "dev")
+	require.NotContains(t, resumed.Annotations, PausedAnnotation)
+	require.NotContains(t, resumed.Annotations, SchedulePausedAnnotation)
+	require.Equal(t, "Normal ScheduledResume Within the active hours of the claim, resumed", <-recorder.Events)
+	require.Equal(t, float64(1), testutil.ToFloat64(ScheduleTransitions.WithLabelValues("pause")))
+	require.Equal(t, float64(1), testutil.ToFloat64(ScheduleTransitions.WithLabelValues("resume")))
+
+	// expiring before the next transition
+	clock.SetTime(time.Date(2026, 10, 20, 17, 0, 0, 0, paris))
+	resumed.Annotations[TTLAnnotation] = "22h30m"
+	require.NoError(t, c.Update(context.Background(), resumed))
+	require.Equal(t, 30*time.Minute, reconcileClaim(t, r, "dev"))
+}
+
+func TestClaimReconciler_ScheduleSources(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Date(2026, 10, 24, 12, 0, 0, 0, time.UTC)) // Saturday
+	policy := newTestPolicy("office-hours", v1alpha1.ClaimLifecyclePolicySpec{
+		Schedule: &v1alpha1.ClaimSchedule{Start: "0 8 * * mon-fri", Stop: "0 18 * * mon-fri"},
+	})
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		policy       *v1alpha1.ClaimLifecyclePolicy
+		expectPaused bool
+		expectEvent  string
+	}{
+		{
+			name:         "policy",
+			policy:       policy,
+			expectPaused: true,
+			expectEvent:  "Normal ScheduledPause ",
+		},
+		{
+			name:        "annotations override the policy",
+			annotations: map[string]string{ScheduleStartAnnotation: "0 0 * * *", ScheduleStopAnnotation: "59 23 * * *"},
+			policy:      policy,
+		},
+		{
+			name:        "invalid schedule",
+			annotations: map[string]string{ScheduleStartAnnotation: "0 8 * * mon-fri", ScheduleStopAnnotation: "at 6pm"},
+			expectEvent: "Warning InvalidSchedule Ignoring the schedule of the claim annotations",
+		},
+		{
+			name:        "paused by someone else",
+			annotations: map[string]string{PausedAnnotation: "true"},
+			policy:      policy,
+		},
+		{
+			name:        "schedule removed",
+			annotations: map[string]string{PausedAnnotation: "true", SchedulePausedAnnotation: "2026-10-23T18:00:00Z"},
+			expectEvent: "Normal ScheduledResume The schedule was removed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []client.Object{newScheduledClaim("dev", clock, tt.annotations)}
+			if tt.policy != nil {
+				objs = append(objs, tt.policy.DeepCopy())
+			}
+			c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(objs...).Build()
+			r, recorder := newHibernateReconciler(c)
+			r.Clock = clock
+
+			reconcileClaim(t, r, "dev")
+			claim := getClaim(t, c, "dev")
+			require.Equal(t, tt.expectPaused, schedulePaused(claim))
+			if tt.expectEvent == "" {
+				require.Empty(t, recorder.Events)
+				return
+			}
+			require.Contains(t, <-recorder.Events, tt.expectEvent)
+		})
+	}
+}
+
+func TestClaimReconciler_DeleteScheduled(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Date(2026, 10, 24, 12, 0, 0, 0, time.UTC))
+	claim := newScheduledClaim("expired", clock, map[string]string{
+		TTLAnnotation:            "30m",
+		PausedAnnotation:         "true",
+		SchedulePausedAnnotation: "2026-10-23T18:00:00Z",
+	})
+	var pausedOnDelete bool
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(claim).WithInterceptorFuncs(interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			_, pausedOnDelete = obj.GetAnnotations()[PausedAnnotation]
+			return c.Delete(ctx, obj, opts...)
+		},
+	}).Build()
+	r, _ := newHibernateReconciler(c)
+	r.Clock = clock
+	r.GracePeriod = 0
+
+	reconcileClaim(t, r, "expired")
+	require.False(t, pausedOnDelete, "Crossplane does not delete paused claims")
+	require.True(t, claimGone(t, c, "expired"))
+	require.Equal(t, float64(1), testutil.ToFloat64(DeletedClaims.WithLabelValues()))
+}
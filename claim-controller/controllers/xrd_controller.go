@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// XRDGVK is the Crossplane CompositeResourceDefinition offering claim kinds
+var XRDGVK = schema.GroupVersionKind{Group: "apiextensions.crossplane.io", Version: "v1", Kind: "CompositeResourceDefinition"}
+
+// ClaimWatcher starts and stops the watches of the claim controller on a claim kind
+type ClaimWatcher interface {
+	Watch(gvk schema.GroupVersionKind) error
+	Unwatch(ctx context.Context, gvk schema.GroupVersionKind) error
+}
+
+// XRDReconciler watches the claim kinds offered by CompositeResourceDefinitions, so claims of XRDs added after
+// the controller started expire too
+type XRDReconciler struct {
+	client.Client
+	Log     logr.Logger
+	Kinds   *ClaimKinds
+	Watcher ClaimWatcher
+	// Selector restricts lifecycle management to the XRDs whose labels match, every XRD when nil
+	Selector labels.Selector
+}
+
+func (r *XRDReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("XRD", req.Name)
+
+	xrd := &unstructured.Unstructured{}
+	xrd.SetGroupVersionKind(XRDGVK)
+	if err := r.Get(ctx, req.NamespacedName, xrd); client.IgnoreNotFound(err) != nil {
+		log.Error(err, "failed to get XRD")
+		return ctrl.Result{}, err
+	} else if err != nil {
+		return ctrl.Result{}, r.unwatch(ctx, req.Name, log)
+	}
+
+	gvk, ok := r.claimKind(xrd)
+	if !ok {
+		return ctrl.Result{}, r.unwatch(ctx, req.Name, log)
+	}
+	if current, ok := r.Kinds.Get(req.Name); ok {
+		if current == gvk {
+			return ctrl.Result{}, nil
+		}
+		// the XRD now offers another version or kind
+		if err := r.unwatch(ctx, req.Name, log); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if err := r.Watcher.Watch(gvk); err != nil {
+		log.Error(err, "failed to watch claims", "kind", gvk.String())
+		return ctrl.Result{}, err
+	}
+	r.Kinds.Add(req.Name, gvk)
+	log.Info("watching claims", "kind", gvk.String())
+	return ctrl.Result{}, nil
+}
+
+func (r *XRDReconciler) unwatch(ctx context.Context, xrd string, log logr.Logger) error {
+	gvk, ok := r.Kinds.Remove(xrd)
+	if !ok {
+		return nil
+	}
+	if err := r.Watcher.Unwatch(ctx, gvk); err != nil {
+		log.Error(err, "failed to stop watching claims", "kind", gvk.String())
+		r.Kinds.Add(xrd, gvk) // retried with the next reconcile
+		return err
+	}
+	log.Info("stopped watching claims", "kind", gvk.String())
+	return nil
+}
+
+// claimKind is the claim kind an XRD offers once Crossplane established its CRD, at its referenceable version.
+// XRDs being deleted, without claims or not matching Selector offer none.
+func (r *XRDReconciler) claimKind(xrd *unstructured.Unstructured) (schema.GroupVersionKind, bool) {
+	if !xrd.GetDeletionTimestamp().IsZero() {
+		return schema.GroupVersionKind{}, false
+	}
+	if r.Selector != nil && !r.Selector.Matches(labels.Set(xrd.GetLabels())) {
+		return schema.GroupVersionKind{}, false
+	}
+	group, _, _ := unstructured.NestedString(xrd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(xrd.Object, "spec", "claimNames", "kind")
+	if group == "" || kind == "" || !offered(xrd) {
+		return schema.GroupVersionKind{}, false
+	}
+	versions, _, _ := unstructured.NestedSlice(xrd.Object, "spec", "versions")
+	for _, v := range versions {
+		version, _ := v.(map[string]any)
+		if referenceable, _ := version["referenceable"].(bool); referenceable {
+			name, _ := version["name"].(string)
+			return schema.GroupVersionKind{Group: group, Version: name, Kind: kind}, true
+		}
+	}
+	return schema.GroupVersionKind{}, false
+}
+
+// offered reports whether Crossplane established the claim CRD of an XRD, watching it before would fail
+func offered(xrd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(xrd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, _ := c.(map[string]any)
+		if condition["type"] == "Offered" {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
+
+func (r *XRDReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	xrd := &unstructured.Unstructured{}
+	xrd.SetGroupVersionKind(XRDGVK)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(xrd).
+		Complete(r)
+}
+
+// NewClaimWatcher starts the watches of c on claim kinds in the informers of cache
+func NewClaimWatcher(c controller.TypedController[ClaimRequest], cache cache.Cache) ClaimWatcher {
+	return &claimWatcher{controller: c, cache: cache}
+}
+
+type claimWatcher struct {
+	controller controller.TypedController[ClaimRequest]
+	cache      cache.Cache
+}
+
+// Watch enqueues the claims of a kind with their kind, names only being unique per kind
+func (w *claimWatcher) Watch(gvk schema.GroupVersionKind) error {
+	return w.controller.Watch(source.TypedKind(w.cache, client.Object(claimObject(gvk)),
+		handler.TypedEnqueueRequestsFromMapFunc(func(_ context.Context, obj client.Object) []ClaimRequest {
+			return []ClaimRequest{{GVK: gvk, NamespacedName: client.ObjectKeyFromObject(obj)}}
+		})))
+}
+
+// Unwatch stops the informer of a claim kind, and with it the event handler of the controller
+func (w *claimWatcher) Unwatch(ctx context.Context, gvk schema.GroupVersionKind) error {
+	if err := w.cache.RemoveInformer(ctx, claimObject(gvk)); err != nil {
+		return fmt.Errorf("failed to remove the informer of %s: %w", gvk.Kind, err)
+	}
+	return nil
+}
+
+func claimObject(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	return u
+}
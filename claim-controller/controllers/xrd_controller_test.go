@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeWatcher records the claim kinds watched
+type fakeWatcher struct {
+	watched map[schema.GroupVersionKind]bool
+}
+
+func (w *fakeWatcher) Watch(gvk schema.GroupVersionKind) error {
+	w.watched[gvk] = true
+	return nil
+}
+
+func (w *fakeWatcher) Unwatch(_ context.Context, gvk schema.GroupVersionKind) error {
+	delete(w.watched, gvk)
+	return nil
+}
+
+func newTestXRD(name, claimKind string, offered bool, lbls map[string]string) *unstructured.Unstructured {
+	status := "False"
+	if offered {
+		status = "True"
+	}
+	xrd := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"group":      APIGroup,
+			"claimNames": map[string]any{"kind": claimKind},
+			"versions": []any{
+				map[string]any{"name": "v1alpha1", "served": true, "referenceable": false},
+				map[string]any{"name": "v1beta1", "served": true, "referenceable": true},
+			},
+		},
+		"status": map[string]any{
+			"conditions": []any{map[string]any{"type": "Offered", "status": status}},
+		},
+	}}
+	xrd.SetGroupVersionKind(XRDGVK)
+	xrd.SetName(name)
+	xrd.SetLabels(lbls)
+	return xrd
+}
+
+func newXRDReconciler(selector labels.Selector, xrds ...client.Object) (*XRDReconciler, *fakeWatcher) {
+	scheme := runtime.NewScheme()
+	watcher := &fakeWatcher{watched: map[schema.GroupVersionKind]bool{}}
+	return &XRDReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(xrds...).Build(),
+		Log:      zap.New(zap.UseDevMode(true)),
+		Kinds:    NewClaimKinds(),
+		Watcher:  watcher,
+		Selector: selector,
+	}, watcher
+}
+
+func reconcileXRD(t *testing.T, r *XRDReconciler, name string) {
+	t.Helper()
+	_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+	require.NoError(t, err)
+}
+
+func TestXRDReconciler_Reconcile(t *testing.T) {
+	model := schema.GroupVersionKind{Group: APIGroup, Version: "v1beta1", Kind: "ModelDeploymentClaim"}
+	tests := []struct {
+		name     string
+		xrd      *unstructured.Unstructured
+		selector labels.Selector
+		expect   []schema.GroupVersionKind
+	}{
+		{
+			name:   "watches the referenceable version of an offered claim kind",
+			xrd:    newTestXRD("modeldeployments", "ModelDeploymentClaim", true, nil),
+			expect: []schema.GroupVersionKind{model},
+		},
+		{
+			name:   "waits for the claim kind to be offered",
+			xrd:    newTestXRD("modeldeployments", "ModelDeploymentClaim", false, nil),
+			expect: []schema.GroupVersionKind{},
+		},
+		{
+			name:   "ignores XRDs without claims",
+			xrd:    newTestXRD("composites", "", true, nil),
+			expect: []schema.GroupVersionKind{},
+		},
+		{
+			name:     "ignores XRDs not opted in",
+			xrd:      newTestXRD("modeldeployments", "ModelDeploymentClaim", true, nil),
+			selector: labels.SelectorFromSet(labels.Set{"platform.example.org/lifecycle": "managed"}),
+			expect:   []schema.GroupVersionKind{},
+		},
+		{
+			name:     "watches XRDs opted in",
+			xrd:      newTestXRD("modeldeployments", "ModelDeploymentClaim", true, map[string]string{"platform.example.org/lifecycle": "managed"}),
+			selector: labels.SelectorFromSet(labels.Set{"platform.example.org/lifecycle": "managed"}),
+			expect:   []schema.GroupVersionKind{model},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, watcher := newXRDReconciler(tt.selector, tt.xrd)
+			reconcileXRD(t, r, tt.xrd.GetName())
+			require.Equal(t, tt.expect, r.Kinds.List())
+			require.Len(t, watcher.watched, len(tt.expect))
+		})
+	}
+}
+
+func TestXRDReconciler_Unwatch(t *testing.T) {
+	xrd := newTestXRD("modeldeployments", "ModelDeploymentClaim", true, map[string]string{"platform.example.org/lifecycle": "managed"})
+	r, watcher := newXRDReconciler(nil, xrd)
+	reconcileXRD(t, r, "modeldeployments")
+	require.Len(t, r.Kinds.List(), 1)
+
+	// opting out stops the watch
+	r.Selector = labels.SelectorFromSet(labels.Set{"platform.example.org/lifecycle": "managed"})
+	xrd.SetLabels(nil)
+	require.NoError(t, r.Update(context.Background(), xrd))
+	reconcileXRD(t, r, "modeldeployments")
+	require.Empty(t, r.Kinds.List())
+	require.Empty(t, watcher.watched)
+
+	// so does removing the XRD
+	r.Selector = nil
+	reconcileXRD(t, r, "modeldeployments")
+	require.Len(t, watcher.watched, 1)
+	require.NoError(t, r.Delete(context.Background(), xrd))
+	reconcileXRD(t, r, "modeldeployments")
+	require.Empty(t, r.Kinds.List())
+	require.Empty(t, watcher.watched)
+}
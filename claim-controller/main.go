@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"time"
+	// schedules name IANA time zones, the image has no zoneinfo
+	_ "time/tzdata"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
+	"claim-controller/api/v1alpha1"
+	"claim-controller/archive"
 	"claim-controller/controllers"
 )
 
@@ -21,17 +29,72 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "archive" {
+		if err := runArchive(os.Args[2:], os.Stdout); err != nil {
+			setupLog.Error(err, "archive")
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
+	var defaultTTL, maxTTL, idleTimeout, gracePeriod, deletionTimeout time.Duration
+	var kindTTLs, xrdSelector, warnBefore, hibernatePatches, archiveSpec string
+	var archiveRetention archive.Retention
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
+	flag.DurationVar(&defaultTTL, "default-ttl", controllers.TTLSeconds*time.Second, "The lifetime of claims without a ttl annotation.")
+	flag.StringVar(&kindTTLs, "kind-ttls", "", "Per-kind default lifetimes overriding --default-ttl, i.e. Storage=4h,Compute=1h.")
+	flag.DurationVar(&maxTTL, "max-ttl", 0, "The maximum lifetime of any claim, 0 for no limit.")
+	flag.DurationVar(&idleTimeout, "idle-timeout", 0, "How long claims may go without activity before they expire, whatever their TTL; 0 to expire them on their TTL only.")
+	flag.DurationVar(&gracePeriod, "grace-period", 0, "How long expired claims hibernate before they are deleted, 0 to delete them on expiry.")
+	flag.StringVar(&hibernatePatches, "hibernate-patches", "", `JSON merge patches scaling down hibernated claims by kind, i.e. {"Compute": {"spec": {"instanceType": "t3.nano"}}}; claims of other kinds are paused.`)
+	flag.StringVar(&archiveSpec, "archive", os.Getenv("CLAIM_ARCHIVE"), "Archive claims before deleting them to configmap:<namespace> or dir:<path>, defaults to $CLAIM_ARCHIVE; claims are not archived when empty.")
+	flag.DurationVar(&archiveRetention.MaxAge, "archive-max-age", 30*24*time.Hour, "How long archives are kept, 0 for no limit.")
+	flag.IntVar(&archiveRetention.MaxCount, "archive-max-count", 1000, "How many archives are kept, the oldest being pruned first; 0 for no limit.")
+	flag.DurationVar(&deletionTimeout, "deletion-timeout", 30*time.Minute, "How long deleted claims may wait for their composed resources before they are flagged as stuck, 0 never to flag them.")
+	flag.StringVar(&warnBefore, "warn-before", "", "Lead times of the ExpiringSoon warnings issued before claims are deleted, i.e. 24h,1h,10m.")
+	flag.StringVar(&xrdSelector, "xrd-selector", "", "Only expire the claims of XRDs matching this label selector, i.e. platform.example.org/lifecycle=managed; every XRD when empty.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
+	ttls, err := controllers.ParseKindTTLs(kindTTLs)
+	if err != nil {
+		setupLog.Error(err, "invalid --kind-ttls")
+		os.Exit(1)
+	}
+	leads, err := controllers.ParseLeadTimes(warnBefore)
+	if err != nil {
+		setupLog.Error(err, "invalid --warn-before")
+		os.Exit(1)
+	}
+	patches, err := controllers.ParseHibernatePatches(hibernatePatches)
+	if err != nil {
+		setupLog.Error(err, "invalid --hibernate-patches")
+		os.Exit(1)
+	}
+	var selector labels.Selector
+	if xrdSelector != "" {
+		if selector, err = labels.Parse(xrdSelector); err != nil {
+			setupLog.Error(err, "invalid --xrd-selector")
+			os.Exit(1)
+		}
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+	shutdownTracing, err := controllers.SetupTracing(ctx)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer func() { _ = shutdownTracing(context.Background()) }()
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:           scheme,
 		LeaderElection:   enableLeaderElection,
@@ -43,21 +106,61 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controllers.ClaimReconciler{
-		Client:     mgr.GetClient(),
-		Log:        ctrl.Log.WithName("claim-controller"),
-		TTLSeconds: controllers.TTLSeconds,
-	}).SetupWithManager(mgr); err != nil {
+	// archives are read and written without caching every ConfigMap of the cluster
+	var store archive.Store
+	if archiveSpec != "" {
+		c, err := client.New(mgr.GetConfig(), client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create the archive client")
+			os.Exit(1)
+		}
+		if store, err = archive.Open(archiveSpec, c); err != nil {
+			setupLog.Error(err, "invalid --archive")
+			os.Exit(1)
+		}
+	}
+
+	// claim kinds are discovered from the XRDs offering them
+	kinds := controllers.NewClaimKinds()
+	claimController, err := (&controllers.ClaimReconciler{
+		Client:           mgr.GetClient(),
+		Log:              ctrl.Log.WithName("claim-controller"),
+		Kinds:            kinds,
+		TTLSeconds:       int64(defaultTTL.Seconds()),
+		KindTTLs:         ttls,
+		MaxTTL:           maxTTL,
+		Recorder:         mgr.GetEventRecorderFor("claim-controller"),
+		WarnBefore:       leads,
+		IdleTimeout:      idleTimeout,
+		GracePeriod:      gracePeriod,
+		HibernatePatches: patches,
+		Archive:          store,
+		ArchiveRetention: archiveRetention,
+		APIReader:        mgr.GetAPIReader(),
+		DeletionTimeout:  deletionTimeout,
+	}).SetupWithManager(mgr)
+	if err != nil {
 		setupLog.Error(err, "unable to create controller")
 		os.Exit(1)
 	}
+	if err = (&controllers.XRDReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("xrd-controller"),
+		Kinds:    kinds,
+		Watcher:  controllers.NewClaimWatcher(claimController, mgr.GetCache()),
+		Selector: selector,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create XRD controller")
+		os.Exit(1)
+	}
 
 	controllers.RegisterMetrics()
 
 	setupLog.Info("starting manager")
 
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
+		_ = shutdownTracing(context.Background())
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,196 @@
+// Package schedule evaluates the active windows of claims, bounded by cron expressions in a time zone
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDays bounds the search for the next time of an expression, such as 0 0 29 2 * firing every leap year
+const maxDays = 5 * 366
+
+var (
+	monthNames = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}
+	dayNames   = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+// Cron is a standard 5-field cron expression: minute, hour, day of month, month and day of week. Fields are *,
+// values, ranges and lists of them with an optional /step; months and days of week may be named (jan, mon).
+type Cron struct {
+	minutes, hours, doms, months, dows []bool
+	// standard cron fires on either day field when both are restricted
+	domStar, dowStar bool
+}
+
+// Parse parses a 5-field cron expression, i.e. 0 8 * * mon-fri
+func Parse(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q, expected 5 fields: minute hour day-of-month month day-of-week", expr)
+	}
+	c := &Cron{domStar: fields[2] == "*", dowStar: fields[4] == "*"}
+	var err error
+	if c.minutes, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute in %q: %w", expr, err)
+	}
+	if c.hours, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour in %q: %w", expr, err)
+	}
+	if c.doms, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day of month in %q: %w", expr, err)
+	}
+	if c.months, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("invalid month in %q: %w", expr, err)
+	}
+	// 7 is Sunday as well
+	if c.dows, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("invalid day of week in %q: %w", expr, err)
+	}
+	c.dows[0] = c.dows[0] || c.dows[7]
+	return c, nil
+}
+
+func parseField(field string, lo, hi int, names map[string]int) ([]bool, error) {
+	set := make([]bool, hi+1)
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+		first, last := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if first, err = parseValue(a, lo, hi, names); err != nil {
+				return nil, err
+			}
+			last = first
+			if isRange {
+				if last, err = parseValue(b, lo, hi, names); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				last = hi // 5/15 is 5-59/15
+			}
+			if first > last {
+				return nil, fmt.Errorf("invalid range %q", rng)
+			}
+		}
+		for v := first; v <= last; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+func parseValue(s string, lo, hi int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || v > hi {
+		return 0, fmt.Errorf("invalid value %q, expected %d-%d", s, lo, hi)
+	}
+	return v, nil
+}
+
+func (c *Cron) matchDay(t time.Time) bool {
+	if !c.months[t.Month()] {
+		return false
+	}
+	dom, dow := c.doms[t.Day()], c.dows[t.Weekday()]
+	switch {
+	case c.domStar && c.dowStar:
+		return true
+	case c.domStar:
+		return dow
+	case c.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Next is the first time after after the expression fires, on the wall clock of the location of after; zero
+// when it never does. Times skipped by a daylight saving change fire when the clocks have moved forward, times
+// repeated when they go back fire once.
+func (c *Cron) Next(after time.Time) time.Time {
+	loc := after.Location()
+	y, m, d := after.Date()
+	for i := range maxDays {
+		day := time.Date(y, m, d+i, 12, 0, 0, 0, loc)
+		if !c.matchDay(day) {
+			continue
+		}
+		// time.Date normalizes the wall times skipped by DST, which may then come after later ones of the day
+		var next time.Time
+		for h, ok := range c.hours {
+			if !ok {
+				continue
+			}
+			for minute, ok := range c.minutes {
+				if !ok {
+					continue
+				}
+				t := time.Date(day.Year(), day.Month(), day.Day(), h, minute, 0, 0, loc)
+				if t.After(after) && (next.IsZero() || t.Before(next)) {
+					next = t
+				}
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// Window is active from each time Start fires until Stop next fires, i.e. 0 8 * * mon-fri to 0 18 * * mon-fri
+type Window struct {
+	Start, Stop *Cron
+	Location    *time.Location
+}
+
+// ParseWindow parses the cron expressions bounding active windows in timeZone, an IANA name defaulting to UTC
+func ParseWindow(start, stop, timeZone string) (*Window, error) {
+	if start == "" || stop == "" {
+		return nil, fmt.Errorf("a schedule needs both a start and a stop expression")
+	}
+	w := &Window{Location: time.UTC}
+	var err error
+	if w.Start, err = Parse(start); err != nil {
+		return nil, err
+	}
+	if w.Stop, err = Parse(stop); err != nil {
+		return nil, err
+	}
+	if timeZone != "" {
+		if w.Location, err = time.LoadLocation(timeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timeZone, err)
+		}
+	}
+	return w, nil
+}
+
+// At reports whether now is within an active window, that is whether Stop fires before Start next, and when
+// the window next starts or stops; next is zero when it never changes again.
+func (w *Window) At(now time.Time) (active bool, next time.Time) {
+	now = now.In(w.Location)
+	start, stop := w.Start.Next(now), w.Stop.Next(now)
+	active = !stop.IsZero() && (start.IsZero() || stop.Before(start))
+	switch {
+	case start.IsZero():
+		next = stop
+	case stop.IsZero() || start.Before(stop):
+		next = start
+	default:
+		next = stop
+	}
+	return active, next
+}
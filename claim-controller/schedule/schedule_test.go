@@ -0,0 +1,160 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	for _, expr := range []string{"0 8 * * 1-5", "*/15 * * * *", "0 8,12 1 jan-jun MON", "5/20 0 * * 7", "0 0 29 2 *"} {
+		_, err := Parse(expr)
+		require.NoError(t, err, expr)
+	}
+	for _, expr := range []string{"", "0 8 * *", "60 * * * *", "0 24 * * *", "0 0 0 * *", "0 0 * 13 *", "0 0 * * 8", "*/0 * * * *", "0 18-8 * * *", "0 0 * * fri-mon", "@daily"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	paris := mustLoad(t, "Europe/Paris")
+	tests := []struct {
+		name   string
+		expr   string
+		after  time.Time
+		expect time.Time
+	}{
+		{
+			name:   "later the same day",
+			expr:   "0 8 * * *",
+			after:  time.Date(2026, 10, 19, 7, 59, 30, 0, time.UTC),
+			expect: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "strictly after",
+			expr:   "0 8 * * *",
+			after:  time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC),
+			expect: time.Date(2026, 10, 20, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "skips the weekend",
+			expr:   "0 8 * * mon-fri",
+			after:  time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), // Friday
+			expect: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "steps",
+			expr:   "*/15 9-17 * * *",
+			after:  time.Date(2026, 10, 19, 17, 50, 0, 0, time.UTC),
+			expect: time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "either day field when both are restricted",
+			expr:   "0 0 1 * sun",
+			after:  time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), // Monday
+			expect: time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "leap days",
+			expr:   "0 0 29 2 *",
+			after:  time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			expect: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "never",
+			expr:   "0 0 30 2 *",
+			after:  time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			expect: time.Time{},
+		},
+		{
+			name:   "wall clock of the location",
+			expr:   "0 8 * * *",
+			after:  time.Date(2026, 10, 19, 7, 0, 0, 0, paris),
+			expect: time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC), // CEST
+		},
+		{
+			name:   "across the change to summer time",
+			expr:   "0 8 * * *",
+			after:  time.Date(2026, 3, 28, 9, 0, 0, 0, paris),
+			expect: time.Date(2026, 3, 29, 6, 0, 0, 0, time.UTC), // 23h later
+		},
+		{
+			name:   "across the change to winter time",
+			expr:   "0 8 * * *",
+			after:  time.Date(2026, 10, 24, 9, 0, 0, 0, paris),
+			expect: time.Date(2026, 10, 25, 7, 0, 0, 0, time.UTC), // 25h later
+		},
+		{
+			name:   "skipped by the change to summer time",
+			expr:   "30 2 * * *",
+			after:  time.Date(2026, 3, 29, 0, 0, 0, 0, paris),
+			expect: time.Date(2026, 3, 29, 1, 30, 0, 0, time.UTC), // 03:30 CEST
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse(tt.expr)
+			require.NoError(t, err)
+			require.True(t, tt.expect.Equal(c.Next(tt.after)), "expected %s, got %s", tt.expect, c.Next(tt.after))
+		})
+	}
+}
+
+func TestCron_NextRepeatedOnce(t *testing.T) {
+	paris := mustLoad(t, "Europe/Paris")
+	c, err := Parse("30 2 * * *")
+	require.NoError(t, err)
+
+	// 02:30 happens twice on the change to winter time
+	first := c.Next(time.Date(2026, 10, 25, 0, 0, 0, 0, paris))
+	require.Equal(t, 25, first.Day())
+	second := c.Next(first)
+	require.Equal(t, 26, second.Day())
+}
+
+func TestWindow_At(t *testing.T) {
+	paris := mustLoad(t, "Europe/Paris")
+	w, err := ParseWindow("0 8 * * mon-fri", "0 18 * * mon-fri", "Europe/Paris")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		expectActive bool
+		expectNext   time.Time
+	}{
+		{"before the window", time.Date(2026, 10, 19, 7, 0, 0, 0, paris), false, time.Date(2026, 10, 19, 8, 0, 0, 0, paris)},
+		{"starting", time.Date(2026, 10, 19, 8, 0, 0, 0, paris), true, time.Date(2026, 10, 19, 18, 0, 0, 0, paris)},
+		{"within the window", time.Date(2026, 10, 19, 12, 0, 0, 0, paris), true, time.Date(2026, 10, 19, 18, 0, 0, 0, paris)},
+		{"stopping", time.Date(2026, 10, 19, 18, 0, 0, 0, paris), false, time.Date(2026, 10, 20, 8, 0, 0, 0, paris)},
+		{"weekend", time.Date(2026, 10, 24, 12, 0, 0, 0, paris), false, time.Date(2026, 10, 26, 8, 0, 0, 0, paris)},
+		{"in another time zone", time.Date(2026, 10, 19, 6, 30, 0, 0, time.UTC), true, time.Date(2026, 10, 19, 18, 0, 0, 0, paris)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, next := w.At(tt.now)
+			require.Equal(t, tt.expectActive, active)
+			require.True(t, tt.expectNext.Equal(next), "expected %s, got %s", tt.expectNext, next)
+		})
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	w, err := ParseWindow("0 8 * * *", "0 18 * * *", "")
+	require.NoError(t, err)
+	require.Equal(t, time.UTC, w.Location)
+
+	_, err = ParseWindow("0 8 * * *", "", "")
+	require.Error(t, err)
+	_, err = ParseWindow("0 8 * * *", "0 18 * * *", "Mars/Olympus_Mons")
+	require.ErrorContains(t, err, "invalid time zone")
+}
@@ -18,6 +18,9 @@ import (
 	"github.com/crossplane/function-sdk-go/resource"
 	"github.com/crossplane/function-sdk-go/resource/composed"
 	"github.com/crossplane/function-sdk-go/response"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Function returns whatever response you ask it to.
@@ -25,11 +28,13 @@ type Function struct {
 	fnv1.UnimplementedFunctionRunnerServiceServer
 
 	log logging.Logger
+	// build builds and loads the image of a model, buildAndLoadDockerImage when nil
+	build func(ctx context.Context, userName, requirementsPath string) (string, error)
 }
 
 // RunFunction runs the Function.
 // Crossplane doesn’t validate function input. It’s a good idea for a function to validate its own input.
-func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest) (*fnv1.RunFunctionResponse, error) {
+func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest) (*fnv1.RunFunctionResponse, error) {
 	f.log.Info("Running function", "tag", req.GetMeta().GetTag())
 	f.log.Info("Request", "input", req.String())
 
@@ -58,11 +63,19 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 		return rsp, nil
 	}
 
+	// Continue the trace of the api-server request that created the claim.
+	ctx, span := tracer.Start(contextFromAnnotations(ctx, xr.Resource.GetAnnotations()), "RunFunction", trace.WithAttributes(
+		attribute.String("xr.kind", xr.Resource.GetKind()),
+		attribute.String("xr.name", xr.Resource.GetName()),
+	))
+	defer span.End()
+
 	// Create an updated logger with useful information about the XR.
 	log := f.log.WithValues(
 		"xr-version", xr.Resource.GetAPIVersion(),
 		"xr-kind", xr.Resource.GetKind(),
 		"xr-name", xr.Resource.GetName(),
+		"trace-id", span.SpanContext().TraceID().String(),
 	)
 
 	un, err := xr.Resource.GetString("spec.userName")
@@ -90,8 +103,14 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 	// composed.From uses this to automatically set apiVersion and kind.
 	_ = v1.AddToScheme(composed.Scheme)
 
-	image, err := buildAndLoadDockerImage(un, rp)
+	build := f.build
+	if build == nil {
+		build = buildAndLoadDockerImage
+	}
+	image, err := build(ctx, un, rp)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		response.Fatal(rsp, errors.Wrapf(err, "failed to build and load docker image"))
 		return rsp, nil
 	}
@@ -142,7 +161,10 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 	return rsp, nil
 }
 
-func buildAndLoadDockerImage(userName, requirementsPath string) (string, error) {
+func buildAndLoadDockerImage(ctx context.Context, userName, requirementsPath string) (string, error) {
+	_, span := tracer.Start(ctx, "buildAndLoadDockerImage")
+	defer span.End()
+
 	if userName == "" {
 		return "", fmt.Errorf("empty userName")
 	}
@@ -34,6 +34,7 @@ func TestRunFunction(t *testing.T) {
 		"ResponseIsReturned": {
 			reason: "The Function should run successfully",
 			args: args{
+				ctx: context.Background(),
 				req: &fnv1.RunFunctionRequest{
 					Observed: &fnv1.State{
 						Composite: &fnv1.Resource{
@@ -73,7 +74,9 @@ func TestRunFunction(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			f := &Function{log: logging.NewNopLogger()}
+			f := &Function{log: logging.NewNopLogger(), build: func(_ context.Context, userName, _ string) (string, error) {
+				return userName + "-1:latest", nil // without docker nor KinD
+			}}
 			rsp, err := f.RunFunction(tc.args.ctx, tc.args.req)
 
 			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
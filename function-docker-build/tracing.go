@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// TraceparentAnnotation is set by the api-server on the claim and propagated by Crossplane to the XR
+const TraceparentAnnotation = "platform.example.org/traceparent"
+
+var tracer = otel.Tracer("function-docker-build")
+
+// setupTracing installs the global TracerProvider. OTEL_TRACES_EXPORTER selects otlp, stdout or none (default).
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exp := strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")); exp {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q: must be otlp, stdout or none", exp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot create trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName("function-docker-build")))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create trace resource: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// contextFromAnnotations continues the trace of the api-server request that created the claim, if any
+func contextFromAnnotations(ctx context.Context, annotations map[string]string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tp, ok := annotations[TraceparentAnnotation]
+	if !ok {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": tp})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config is persisted by `platformctl login`
+type Config struct {
+	Server   string `json:"server"`
+	Username string `json:"username"` // default namespace for claims
+	Token    string `json:"token,omitempty"`
+}
+
+// configPath honours $PLATFORMCTL_CONFIG, defaulting to ~/.config/platformctl/config.yaml
+func configPath() (string, error) {
+	if p := os.Getenv("PLATFORMCTL_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "platformctl", "config.yaml"), nil
+}
+
+// LoadConfig returns an empty Config when the file does not exist yet
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	cfg := &Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the config readable only by the current user since it may hold a token
+func (c *Config) Save(path string) error {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("error creating %s: %w", filepath.Dir(path), err)
+	}
+	return os.WriteFile(path, b, 0o600)
+}
@@ -0,0 +1,424 @@
+// Command platformctl requests and manages platform claims through the api-server
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"api-server/pkg/client"
+)
+
+const usage = `platformctl requests and manages platform claims through the api-server.
+
+Usage:
+  platformctl login    --server URL --username NAME [--token TOKEN]
+  platformctl create   KIND NAME --region REGION [--ttl DURATION] [--tag KEY=VALUE]... [--wait] [--wait-timeout 15m]
+  platformctl list     KIND
+  platformctl describe KIND NAME
+  platformctl edit     KIND NAME --region REGION
+  platformctl extend   KIND NAME --by DURATION
+  platformctl connection KIND NAME [--reveal] [--env]
+  platformctl clone    KIND NAME NEW_NAME [--to-namespace NS] [--to-cluster CLUSTER] [--region REGION] [--ttl DURATION] [--tag KEY=VALUE]...
+  platformctl touch    KIND NAME
+  platformctl delete   KIND NAME
+  platformctl clusters
+
+Every command except login accepts:
+  -n, --namespace   namespace of the claims (defaults to the login username)
+  -o, --output      table, json or yaml (default table)
+      --server      api-server URL (defaults to the login server)
+      --cluster     target cluster of a multi-cluster api-server (list spans every cluster when omitted)
+
+KIND is a claim kind such as Storage or Compute.
+`
+
+// pollInterval is how often --wait describes the claim
+var pollInterval = 5 * time.Second
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// cli holds the state shared by every command
+type cli struct {
+	stdout, stderr io.Writer
+	cfgPath        string
+	cfg            *Config
+
+	// common flags
+	server    string
+	namespace string
+	output    string
+	cluster   string
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+
+	c := &cli{stdout: stdout, stderr: stderr}
+	var err error
+	if c.cfgPath, err = configPath(); err == nil {
+		c.cfg, err = LoadConfig(c.cfgPath)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+
+	commands := map[string]func(context.Context, []string) error{
+		"login":      c.login,
+		"create":     c.create,
+		"list":       c.list,
+		"describe":   c.describe,
+		"edit":       c.edit,
+		"extend":     c.extend,
+		"connection": c.connection,
+		"clone":      c.clone,
+		"touch":      c.touch,
+		"delete":     c.delete,
+		"clusters":   c.clusters,
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "error: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	if err := cmd(ctx, args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+// flags returns a FlagSet with the common flags registered
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.StringVar(&c.server, "server", "", "api-server URL")
+	fs.StringVar(&c.namespace, "namespace", "", "namespace of the claims")
+	fs.StringVar(&c.namespace, "n", "", "shorthand for --namespace")
+	fs.StringVar(&c.output, "output", "table", "output format: table, json or yaml")
+	fs.StringVar(&c.output, "o", "table", "shorthand for --output")
+	fs.StringVar(&c.cluster, "cluster", "", "target cluster, see platformctl clusters")
+	return fs
+}
+
+// parse allows flags after positional arguments (platformctl create storage foo --region US)
+// and checks the number of positional arguments
+func parse(fs *flag.FlagSet, args []string, want ...string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != len(want) {
+		return nil, fmt.Errorf("%s expects %d argument(s): %s", fs.Name(), len(want), strings.Join(want, " "))
+	}
+	return positional, nil
+}
+
+// client resolves the server and namespace from flags, falling back to the login config
+func (c *cli) client() (*client.Client, error) {
+	if c.server == "" {
+		c.server = c.cfg.Server
+	}
+	if c.server == "" {
+		return nil, errors.New("no api-server configured: run platformctl login or pass --server")
+	}
+	if c.namespace == "" {
+		c.namespace = c.cfg.Username
+	}
+	if c.namespace == "" {
+		return nil, errors.New("no namespace: run platformctl login or pass --namespace")
+	}
+	opts := []client.Option{}
+	if c.cfg.Token != "" {
+		opts = append(opts, client.WithToken(c.cfg.Token))
+	}
+	if c.cluster != "" {
+		opts = append(opts, client.WithCluster(c.cluster))
+	}
+	return client.New(c.server, opts...)
+}
+
+func (c *cli) login(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	server := fs.String("server", c.cfg.Server, "api-server URL, i.e. http://localhost:8080")
+	username := fs.String("username", c.cfg.Username, "username, also the default namespace for claims")
+	token := fs.String("token", "", "bearer token sent with every request")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	if *server == "" || *username == "" {
+		return errors.New("login requires --server and --username")
+	}
+
+	// Verify the server is reachable and is an api-server before saving it
+	if _, err := client.New(*server); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(*server, "/")+"/openapi.json", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to reach %s: %w", *server, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s does not look like an api-server: GET /openapi.json returned %d", *server, resp.StatusCode)
+	}
+
+	cfg := &Config{Server: *server, Username: *username, Token: *token}
+	if err := cfg.Save(c.cfgPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Logged in to %s as %s (config saved to %s)\n", *server, *username, c.cfgPath)
+	return nil
+}
+
+func (c *cli) create(ctx context.Context, args []string) error {
+	fs := c.flags("create")
+	region := fs.String("region", "", "region of the claim, i.e. US or EU")
+	ttl := fs.Duration("ttl", 0, "lifetime of the claim, i.e. 4h (defaults to the kind's default)")
+	tags := tagFlag(fs)
+	wait := fs.Bool("wait", false, "wait until the claim is Ready")
+	timeout := fs.Duration("wait-timeout", 15*time.Minute, "how long --wait waits")
+	pos, err := parse(fs, args, "KIND", "NAME")
+	if err != nil {
+		return err
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	req := client.ClaimRequest{Type: pos[0], Name: pos[1], Namespace: c.namespace, Region: *region, Tags: tags}
+	if *ttl > 0 {
+		req.TTL = ttl.String()
+	}
+	claim, err := cl.CreateClaim(ctx, req)
+	if err != nil {
+		return err
+	}
+	if *wait {
+		ctx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+		if claim, err = waitReady(ctx, cl, c.stderr, pos[0], c.namespace, pos[1], pollInterval); err != nil {
+			return err
+		}
+	}
+	return printClaim(c.stdout, c.output, claim)
+}
+
+// tagFlag registers the repeatable --tag KEY=VALUE flag
+func tagFlag(fs *flag.FlagSet) map[string]string {
+	tags := map[string]string{}
+	fs.Func("tag", "cost-allocation tag KEY=VALUE (team, project or cost-center), repeatable", func(v string) error {
+		k, val, ok := strings.Cut(v, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("tag must be KEY=VALUE, got %q", v)
+		}
+		tags[k] = val
+		return nil
+	})
+	return tags
+}
+
+func (c *cli) clone(ctx context.Context, args []string) error {
+	fs := c.flags("clone")
+	toNamespace := fs.String("to-namespace", "", "namespace of the clone (defaults to the source's)")
+	toCluster := fs.String("to-cluster", "", "cluster of the clone (defaults to the source's)")
+	region := fs.String("region", "", "region of the clone (defaults to the source's)")
+	ttl := fs.Duration("ttl", 0, "lifetime of the clone (defaults to the source's when allowed)")
+	tags := tagFlag(fs)
+	pos, err := parse(fs, args, "KIND", "NAME", "NEW_NAME")
+	if err != nil {
+		return err
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	req := client.CloneClaimRequest{Name: pos[2], Namespace: *toNamespace, Region: *region, Tags: tags, Cluster: *toCluster}
+	if *ttl > 0 {
+		req.TTL = ttl.String()
+	}
+	claim, err := cl.CloneClaim(ctx, pos[0], c.namespace, pos[1], req)
+	if err != nil {
+		return err
+	}
+	return printClaim(c.stdout, c.output, claim)
+}
+
+func (c *cli) list(ctx context.Context, args []string) error {
+	fs := c.flags("list")
+	pos, err := parse(fs, args, "KIND")
+	if err != nil {
+		return err
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+	claims, err := cl.ListClaims(ctx, c.namespace, pos[0])
+	if err != nil {
+		return err
+	}
+	return printClaims(c.stdout, c.output, claims)
+}
+
+func (c *cli) clusters(ctx context.Context, args []string) error {
+	fs := c.flags("clusters")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+	clusters, err := cl.Clusters(ctx)
+	if err != nil {
+		return err
+	}
+	return printClusters(c.stdout, c.output, clusters)
+}
+
+func (c *cli) describe(ctx context.Context, args []string) error {
+	fs := c.flags("describe")
+	pos, err := parse(fs, args, "KIND", "NAME")
+	if err != nil {
+		return err
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+	claim, err := cl.GetClaim(ctx, pos[0], c.namespace, pos[1])
+	if err != nil {
+		return err
+	}
+	return printClaim(c.stdout, c.output, claim)
+}
+
+func (c *cli) connection(ctx context.Context, args []string) error {
+	fs := c.flags("connection")
+	reveal := fs.Bool("reveal", false, "show credentials instead of masking them (audited)")
+	env := fs.Bool("env", false, "print the revealed details as shell exports, i.e. eval \"$(platformctl connection ...)\"")
+	pos, err := parse(fs, args, "KIND", "NAME")
+	if err != nil {
+		return err
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+	conn, err := cl.Connection(ctx, pos[0], c.namespace, pos[1], *reveal || *env)
+	if err != nil {
+		return err
+	}
+	if *env {
+		_, err = io.WriteString(c.stdout, conn.Env)
+		return err
+	}
+	return printConnection(c.stdout, c.output, conn)
+}
+
+func (c *cli) edit(ctx context.Context, args []string) error {
+	fs := c.flags("edit")
+	region := fs.String("region", "", "new region of the claim")
+	pos, err := parse(fs, args, "KIND", "NAME")
+	if err != nil {
+		return err
+	}
+	if *region == "" {
+		return errors.New("edit requires --region")
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+	claim, err := cl.UpdateClaim(ctx, pos[0], c.namespace, pos[1], *region)
+	if err != nil {
+		return err
+	}
+	return printClaim(c.stdout, c.output, claim)
+}
+
+func (c *cli) extend(ctx context.Context, args []string) error {
+	fs := c.flags("extend")
+	by := fs.Duration("by", time.Hour, "how much to add to the TTL")
+	pos, err := parse(fs, args, "KIND", "NAME")
+	if err != nil {
+		return err
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+	claim, err := cl.ExtendClaim(ctx, pos[0], c.namespace, pos[1], *by)
+	if err != nil {
+		return err
+	}
+	return printClaim(c.stdout, c.output, claim)
+}
+
+// touch records activity on a claim, keeping it from being deleted as idle
+func (c *cli) touch(ctx context.Context, args []string) error {
+	fs := c.flags("touch")
+	pos, err := parse(fs, args, "KIND", "NAME")
+	if err != nil {
+		return err
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+	if err := cl.TouchClaim(ctx, pos[0], c.namespace, pos[1]); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "%s/%s touched\n", pos[0], pos[1])
+	return nil
+}
+
+func (c *cli) delete(ctx context.Context, args []string) error {
+	fs := c.flags("delete")
+	pos, err := parse(fs, args, "KIND", "NAME")
+	if err != nil {
+		return err
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+	if err := cl.DeleteClaim(ctx, pos[0], c.namespace, pos[1]); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "%s/%s deleted\n", pos[0], pos[1])
+	return nil
+}
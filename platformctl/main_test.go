@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer emulates the api-server claims API; the claim becomes Ready on the third describe
+func fakeServer(t *testing.T) *httptest.Server {
+	var describes atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"openapi":"3.0.3"}`))
+	})
+	mux.HandleFunc("POST /api/v1/claims", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "dev", req["namespace"])
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"name": req["name"], "kind": "Storage", "namespace": "dev", "region": req["region"], "status": "Unknown"})
+	})
+	mux.HandleFunc("GET /api/v1/claims", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"name":"mystorage","kind":"Storage","namespace":"dev","region":"US","status":"Ready","ttl":"10m0s"}]`))
+	})
+	mux.HandleFunc("GET /api/v1/claims/{type}/{ns}/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if describes.Add(1) < 3 {
+			_, _ = w.Write([]byte(`{"name":"mystorage","status":"NotReady","conditions":[{"type":"Synced","status":"True"},{"type":"Ready","status":"False","reason":"Creating"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"mystorage","status":"Ready","conditions":[{"type":"Synced","status":"True"},{"type":"Ready","status":"True"}]}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLoginThenList(t *testing.T) {
+	srv := fakeServer(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv("PLATFORMCTL_CONFIG", path)
+	ctx := context.Background()
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run(ctx, []string{"login", "--server", srv.URL, "--username", "dev"}, &stdout, &stderr), stderr.String())
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL, cfg.Server)
+
+	stdout.Reset()
+	require.Equal(t, 0, run(ctx, []string{"list", "storage", "-o", "json"}, &stdout, &stderr), stderr.String())
+	var claims []map[string]any
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &claims))
+	assert.Equal(t, "mystorage", claims[0]["name"])
+
+	stdout.Reset()
+	require.Equal(t, 0, run(ctx, []string{"list", "storage"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "NAME")
+	assert.Contains(t, stdout.String(), "10m0s")
+}
+
+func TestCreateWait(t *testing.T) {
+	srv := fakeServer(t)
+	t.Setenv("PLATFORMCTL_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	pollInterval = time.Millisecond
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"create", "storage", "mystorage", "--server", srv.URL, "-n", "dev", "--region", "US", "--wait", "-o", "yaml"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	assert.Contains(t, stderr.String(), "Ready=False(Creating)")
+	assert.Contains(t, stdout.String(), "status: Ready")
+}
+
+func TestMissingServer(t *testing.T) {
+	t.Setenv("PLATFORMCTL_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 1, run(context.Background(), []string{"list", "storage"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "platformctl login")
+}
+
+func TestListAcrossClusters(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/claims", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cluster") == "staging" {
+			_, _ = w.Write([]byte(`[{"name":"b","kind":"Storage","namespace":"dev","region":"EU","status":"Ready","cluster":"staging"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name":"a","kind":"Storage","namespace":"dev","region":"US","status":"Ready","cluster":"dev"},{"name":"b","kind":"Storage","namespace":"dev","region":"EU","status":"Ready","cluster":"staging"}]`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	t.Setenv("PLATFORMCTL_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	ctx := context.Background()
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run(ctx, []string{"list", "storage", "--server", srv.URL, "-n", "dev"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "CLUSTER")
+	assert.Regexp(t, `staging\s+b\s+Storage`, stdout.String())
+
+	stdout.Reset()
+	require.Equal(t, 0, run(ctx, []string{"list", "storage", "--server", srv.URL, "-n", "dev", "--cluster", "staging", "-o", "json"}, &stdout, &stderr), stderr.String())
+	var claims []map[string]any
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &claims))
+	require.Len(t, claims, 1)
+	assert.Equal(t, "staging", claims[0]["cluster"])
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"api-server/pkg/client"
+
+	"sigs.k8s.io/yaml"
+)
+
+// printClaims renders claims as table, json or yaml
+func printClaims(w io.Writer, format string, claims []client.Claim) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(claims)
+	case "yaml":
+		b, err := yaml.Marshal(claims)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case "table", "":
+		// the CLUSTER column only shows with a multi-cluster api-server
+		clustered := slices.ContainsFunc(claims, func(c client.Claim) bool { return c.Cluster != "" })
+		tw := tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+		if clustered {
+			fmt.Fprint(tw, "CLUSTER\t")
+		}
+		fmt.Fprintln(tw, "NAME\tKIND\tNAMESPACE\tREGION\tSTATUS\tTTL\tEXPIRES\tAGE")
+		for _, c := range claims {
+			if clustered {
+				fmt.Fprintf(tw, "%s\t", orDash(c.Cluster))
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				c.Name, c.Kind, c.Namespace, c.Region, c.Status, orDash(c.TTL), expires(c.ExpiresAt), age(c.CreatedAt))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q: must be one of table, json, yaml", format)
+	}
+}
+
+// printClaim renders a single claim; the table format includes its conditions
+func printClaim(w io.Writer, format string, c *client.Claim) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+	case "yaml":
+		b, err := yaml.Marshal(c)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case "table", "":
+	default:
+		return fmt.Errorf("unknown output format %q: must be one of table, json, yaml", format)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Name:\t%s\n", c.Name)
+	fmt.Fprintf(tw, "Kind:\t%s\n", c.Kind)
+	fmt.Fprintf(tw, "Namespace:\t%s\n", c.Namespace)
+	if c.Cluster != "" {
+		fmt.Fprintf(tw, "Cluster:\t%s\n", c.Cluster)
+	}
+	fmt.Fprintf(tw, "Region:\t%s\n", c.Region)
+	fmt.Fprintf(tw, "Status:\t%s\n", c.Status)
+	fmt.Fprintf(tw, "TTL:\t%s\n", orDash(c.TTL))
+	if !c.ExpiresAt.IsZero() {
+		fmt.Fprintf(tw, "Expires:\t%s (%s)\n", c.ExpiresAt.Local().Format(time.RFC3339), expires(c.ExpiresAt))
+	}
+	fmt.Fprintf(tw, "Renewals:\t%d\n", c.Renewals)
+	if !c.LastActivity.IsZero() {
+		fmt.Fprintf(tw, "Last activity:\t%s ago\n", age(c.LastActivity))
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Tags)) {
+		fmt.Fprintf(tw, "Tag %s:\t%s\n", k, c.Tags[k])
+	}
+	if g := c.GitOps; g != nil {
+		fmt.Fprintf(tw, "GitOps:\t%s %s@%s\n", g.Sync, g.Path, orDash(shortRevision(g.Revision)))
+	}
+	fmt.Fprintf(tw, "Age:\t%s\n", age(c.CreatedAt))
+	if len(c.Conditions) > 0 {
+		fmt.Fprintln(tw, "Conditions:")
+		fmt.Fprintln(tw, "  TYPE\tSTATUS\tREASON\tMESSAGE")
+		for _, cond := range c.Conditions {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", cond.Type, cond.Status, orDash(cond.Reason), orDash(cond.Message))
+		}
+	}
+	return tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// age is formatted like kubectl, i.e. 5m or 3h
+func age(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// expires is the time left before the claim-controller deletes the claim, i.e. in 5m
+func expires(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if time.Until(t) <= 0 {
+		return "expired"
+	}
+	return "in " + age(time.Now().Add(-time.Until(t)))
+}
+
+// printConnection renders the connection details of a claim, one key per row
+func printConnection(w io.Writer, format string, conn *client.Connection) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(conn)
+	case "yaml":
+		b, err := yaml.Marshal(conn)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case "table", "":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "KEY\tVALUE")
+		for _, d := range conn.Details {
+			fmt.Fprintf(tw, "%s\t%s\n", d.Key, d.Value)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q: must be one of table, json, yaml", format)
+	}
+}
+
+func shortRevision(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// printClusters renders the clusters of a multi-cluster api-server with their health
+func printClusters(w io.Writer, format string, clusters []client.ClusterStatus) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(clusters)
+	case "yaml":
+		b, err := yaml.Marshal(clusters)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case "table", "":
+		if len(clusters) == 0 {
+			fmt.Fprintln(w, "The api-server manages a single cluster")
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tENVIRONMENT\tDEFAULT\tHEALTHY\tFAILURES")
+		for _, c := range clusters {
+			fmt.Fprintf(tw, "%s\t%s\t%t\t%t\t%s\n", c.Name, orDash(c.Environment), c.Default, c.Healthy, orDash(strings.Join(c.Failures, "; ")))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q: must be one of table, json, yaml", format)
+	}
+}
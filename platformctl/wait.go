@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"api-server/pkg/client"
+)
+
+// waitReady polls the claim until its Ready condition is True, printing a progress line
+// to w every time the observed conditions change
+func waitReady(ctx context.Context, c *client.Client, w io.Writer, kind, ns, name string, interval time.Duration) (*client.Claim, error) {
+	start := time.Now()
+	last := ""
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		claim, err := c.GetClaim(ctx, kind, ns, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if progress := summarize(claim); progress != last {
+			fmt.Fprintf(w, "[%6s] %s/%s: %s\n", time.Since(start).Round(time.Second), kind, name, progress)
+			last = progress
+		}
+		if claim.Ready() {
+			return claim, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return claim, fmt.Errorf("timed out waiting for %s/%s to become Ready: %w", kind, name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// summarize renders the conditions of a claim, i.e. Synced=True Ready=False(Creating)
+func summarize(c *client.Claim) string {
+	if len(c.Conditions) == 0 {
+		return "waiting for Crossplane to report conditions"
+	}
+	parts := make([]string, 0, len(c.Conditions))
+	for _, cond := range c.Conditions {
+		s := cond.Type + "=" + cond.Status
+		if cond.Status != "True" && cond.Reason != "" {
+			s += "(" + cond.Reason + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}